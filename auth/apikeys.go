@@ -0,0 +1,34 @@
+// auth/apikeys.go
+package auth
+
+import (
+	"errors"
+	"inventory_system/models"
+)
+
+// APIKeyHeader is the request header programmatic clients send their key in
+const APIKeyHeader = "X-API-Key"
+
+// ErrInvalidAPIKey is returned for keys that are unknown, expired or revoked
+var ErrInvalidAPIKey = errors.New("invalid, expired or revoked API key")
+
+// KeyAuthenticator looks up the API key sent with a request, recording its use
+type KeyAuthenticator interface {
+	Authenticate(key string) (models.APIKey, error)
+}
+
+// keyClaims describes an API key the way a token describes a user, so that
+// handlers and Require treat both alike
+func keyClaims(key models.APIKey) *Claims {
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return &Claims{
+		Username:  key.Name,
+		TenantID:  key.TenantID,
+		Locations: key.Locations,
+		Scopes:    scopes,
+		APIKeyID:  key.ID,
+	}
+}
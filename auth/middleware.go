@@ -0,0 +1,68 @@
+// auth/middleware.go
+package auth
+
+import (
+	"errors"
+	"inventory_system/database"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// claimsKey is the gin context key the signed-in user's claims are stored under
+const claimsKey = "auth.claims"
+
+// Middleware rejects requests without a valid access token in the
+// Authorization header or API key in the X-API-Key header, and makes the
+// token's or key's claims available to handlers
+func Middleware(tokens *Tokens, keys KeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			record, err := keys.Authenticate(key)
+			if errors.Is(err, ErrInvalidAPIKey) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid, expired or revoked API key"})
+				return
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
+				return
+			}
+
+			setClaims(c, keyClaims(record))
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token or API key"})
+			return
+		}
+
+		claims, err := tokens.Parse(token, AccessToken)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// setClaims makes the claims available to handlers, scopes the request's
+// queries to their tenant and attributes its database writes to them
+func setClaims(c *gin.Context, claims *Claims) {
+	c.Set(claimsKey, claims)
+	ctx := database.WithTenant(c.Request.Context(), claims.TenantID)
+	c.Request = c.Request.WithContext(database.WithActor(ctx, claims.Actor()))
+}
+
+// CurrentUser returns the claims of the signed-in user, or nil outside the middleware
+func CurrentUser(c *gin.Context) *Claims {
+	claims, _ := c.Get(claimsKey)
+	current, _ := claims.(*Claims)
+	return current
+}
@@ -0,0 +1,138 @@
+// auth/tokens.go
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"inventory_system/config"
+	"inventory_system/models"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token types; an access token authorises requests and a refresh token only
+// obtains new tokens
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, wrongly signed,
+// expired or of the wrong type
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims are the contents of a signed token
+type Claims struct {
+	Username string `json:"username"`
+	Type     string `json:"typ"`
+	Role     string `json:"role,omitempty"`
+	// TenantID is the tenant whose data the token gives access to
+	TenantID uint `json:"tid,omitempty"`
+	// Locations restricts stock changes to these locations when not empty
+	Locations []string `json:"locations,omitempty"`
+	// Scopes replace the role's permissions for requests made with an API key
+	Scopes []string `json:"-"`
+	// APIKeyID is set for requests made with an API key instead of a token
+	APIKeyID uint `json:"-"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the ID of the user the token was issued to
+func (c *Claims) UserID() uint {
+	id, _ := strconv.ParseUint(c.Subject, 10, 32)
+	return uint(id)
+}
+
+// Actor names the user or API key in the audit log
+func (c *Claims) Actor() string {
+	if c.APIKeyID != 0 {
+		return "api_key:" + c.Username
+	}
+	return "user:" + c.Username
+}
+
+// TokenPair is the response to a successful login or refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int `json:"expires_in"`
+}
+
+// Tokens issues and verifies tokens signed with HMAC-SHA256
+type Tokens struct {
+	Secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// NewTokens returns the token issuer for the auth settings. Without a
+// configured secret a random one is used, so tokens stop working on restart.
+func NewTokens(cfg config.AuthConfig) *Tokens {
+	secret := []byte(cfg.Secret)
+	if len(secret) == 0 {
+		log.Println("Warning: no auth secret is configured; tokens are signed with a random key and expire when the server restarts")
+		secret = make([]byte, config.MinSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate auth secret: %v", err)
+		}
+	}
+	return &Tokens{Secret: secret, AccessTTL: time.Duration(cfg.AccessTTL), RefreshTTL: time.Duration(cfg.RefreshTTL)}
+}
+
+// Issue signs a new access and refresh token for a user
+func (t *Tokens) Issue(user models.User) (TokenPair, error) {
+	access, err := t.sign(user, AccessToken, t.AccessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := t.sign(user, RefreshToken, t.RefreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(t.AccessTTL.Seconds()),
+	}, nil
+}
+
+// Parse verifies a token of the given type and returns its claims
+func (t *Tokens) Parse(token, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return t.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.Type != tokenType || claims.UserID() == 0 {
+		return nil, ErrInvalidToken
+	}
+	// Access tokens issued before tenants existed name none; refreshing
+	// replaces them, since the refresh reloads the user
+	if tokenType == AccessToken && claims.TenantID == 0 {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// sign builds and signs a token of the given type for a user
+func (t *Tokens) sign(user models.User, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Username:  user.Username,
+		Type:      tokenType,
+		Role:      user.Role,
+		TenantID:  user.TenantID,
+		Locations: user.Locations,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.Secret)
+}
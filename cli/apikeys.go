@@ -0,0 +1,136 @@
+// cli/apikeys.go
+package cli
+
+import (
+	"fmt"
+	"inventory_system/auth"
+	"inventory_system/database"
+	"inventory_system/services"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runCreateAPIKey issues an API key and prints it; the key cannot be shown again
+func runCreateAPIKey(args []string) error {
+	fs := newFlagSet("create-api-key")
+	scopes := fs.String("scopes", "", "comma-separated permissions the key grants: "+strings.Join(auth.Permissions(auth.RoleAdmin), ", "))
+	locations := fs.String("locations", "", "comma-separated locations the key may change stock at; all when empty")
+	expires := fs.Duration("expires", 0, "how long the key works, such as 720h; it never expires when zero")
+	tenant := tenantFlag(fs)
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 || *expires < 0 {
+		return usageError(fs)
+	}
+
+	request := services.APIKeyRequest{
+		Name:      args[0],
+		Scopes:    strings.Split(*scopes, ","),
+		Locations: strings.Split(*locations, ","),
+	}
+	if *expires > 0 {
+		expiresAt := time.Now().Add(*expires)
+		request.ExpiresAt = &expiresAt
+	}
+
+	db := database.Initialize(cfg)
+	ctx, err := tenantContext(db, *tenant)
+	if err != nil {
+		return err
+	}
+	record, key, err := (&services.GormAPIKeyService{DB: db}).Create(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created API key %d (%s) for %s with %s\n", record.ID, record.Prefix, record.Name, strings.Join(record.Scopes, ", "))
+	if record.ExpiresAt != nil {
+		fmt.Printf("Expires %s\n", record.ExpiresAt.Format(time.RFC3339))
+	}
+	fmt.Println(key)
+	fmt.Println("Store the key now; only its hash is kept.")
+	return nil
+}
+
+// runListAPIKeys prints the API keys as a table
+func runListAPIKeys(args []string) error {
+	fs := newFlagSet("list-api-keys")
+	tenant := tenantFlag(fs)
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usageError(fs)
+	}
+
+	db := database.Initialize(cfg)
+	ctx, err := tenantContext(db, *tenant)
+	if err != nil {
+		return err
+	}
+	keys, err := (&services.GormAPIKeyService{DB: db}).List(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPREFIX\tNAME\tSCOPES\tLOCATIONS\tEXPIRES\tLAST USED\tREVOKED")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Prefix, key.Name,
+			strings.Join(key.Scopes, ","), orDash(strings.Join(key.Locations, ",")),
+			formatTime(key.ExpiresAt), formatTime(key.LastUsedAt), formatTime(key.RevokedAt))
+	}
+	return w.Flush()
+}
+
+// runRevokeAPIKey stops an API key from authenticating
+func runRevokeAPIKey(args []string) error {
+	fs := newFlagSet("revoke-api-key")
+	tenant := tenantFlag(fs)
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError(fs)
+	}
+	id, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return usageError(fs)
+	}
+
+	db := database.Initialize(cfg)
+	ctx, err := tenantContext(db, *tenant)
+	if err != nil {
+		return err
+	}
+	record, err := (&services.GormAPIKeyService{DB: db}).Revoke(ctx, uint(id))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked API key %d (%s) for %s\n", record.ID, record.Prefix, record.Name)
+	return nil
+}
+
+// formatTime formats an optional time for a table, with a dash when it is unset
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// orDash returns value, or a dash when it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
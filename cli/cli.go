@@ -0,0 +1,155 @@
+// cli/cli.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"inventory_system/config"
+	"inventory_system/database"
+	"inventory_system/services"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// command is a subcommand of the inventory_system binary
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists the subcommands in the order they are shown in the usage
+func commands() []command {
+	return []command{
+		{"serve", "[flags]", "run the HTTP server and background jobs (the default)", runServe},
+		{"migrate", "up | down [steps] | status [flags]", "apply, revert or list schema migrations", runMigrate},
+		{"seed", "[flags]", "fill an empty database with generated sample data", runSeed},
+		{"import", "products|inventory FILE [flags]", "create or update records from a CSV or JSON file", runImport},
+		{"export", "products|inventory|orders [flags]", "write records as CSV or JSON", runExport},
+		{"recompute-stock", "[flags]", "rebuild location stock from the bins and report drift", runRecomputeStock},
+		{"create-tenant", "NAME [flags]", "add a business that shares the deployment", runCreateTenant},
+		{"list-tenants", "[flags]", "list tenants with their categories and locations", runListTenants},
+		{"create-user", "USERNAME [flags]", "add a user who can sign in to the API", runCreateUser},
+		{"create-api-key", "NAME -scopes S [flags]", "issue an API key for a programmatic client", runCreateAPIKey},
+		{"list-api-keys", "[flags]", "list API keys with their scopes, expiry and last use", runListAPIKeys},
+		{"revoke-api-key", "ID [flags]", "stop an API key from authenticating", runRevokeAPIKey},
+	}
+}
+
+// errUsage is returned after the usage of a command has been printed
+var errUsage = errors.New("invalid arguments")
+
+// Run executes the command named by the first argument, serve when there is none
+func Run(args []string) error {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		printUsage(os.Stdout)
+		return nil
+	}
+
+	for _, c := range commands() {
+		if c.name == name {
+			err := c.run(args)
+			// -h prints the usage of the command and is not a failure
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+	}
+	printUsage(os.Stderr)
+	return fmt.Errorf("unknown command %q", name)
+}
+
+// printUsage lists the commands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: inventory_system [command] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-16s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Every command accepts the configuration flags; use \"inventory_system <command> -h\" to list them.")
+}
+
+// newFlagSet returns the flag set of a command, with a usage line built from its arguments
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	for _, c := range commands() {
+		if c.name == name {
+			fs.Usage = func() {
+				fmt.Fprintf(fs.Output(), "Usage: inventory_system %s %s\n\n%s\n\nFlags:\n", c.name, c.args, c.summary)
+				fs.PrintDefaults()
+			}
+		}
+	}
+	return fs
+}
+
+// load parses the flags of a command together with the configuration flags and
+// returns the configuration and the positional arguments, which may come
+// before or after the flags
+func load(fs *flag.FlagSet, args []string) (*config.Config, []string, error) {
+	var positional []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	cfg, err := config.LoadFlags(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, append(positional, fs.Args()...), nil
+}
+
+// auditContext attributes the database writes of a command to the operating
+// system user who ran it
+func auditContext() context.Context {
+	actor := "cli"
+	if current, err := user.Current(); err == nil {
+		actor += ":" + current.Username
+	}
+	return database.WithActor(context.Background(), actor)
+}
+
+// tenantFlag adds the -tenant flag of the commands that work on one tenant's data
+func tenantFlag(fs *flag.FlagSet) *string {
+	return fs.String("tenant", "", "name or ID of the tenant to work on; the default tenant when empty")
+}
+
+// findTenant returns the tenant named by the -tenant flag
+func findTenant(db *gorm.DB, reference string) (uint, error) {
+	tenant, err := database.FindTenant(db, reference)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("%w: %q", services.ErrTenantNotFound, reference)
+	}
+	return tenant.ID, err
+}
+
+// tenantContext scopes the queries of a command to the tenant named by the
+// -tenant flag and attributes its writes to the operating system user
+func tenantContext(db *gorm.DB, reference string) (context.Context, error) {
+	tenantID, err := findTenant(db, reference)
+	if err != nil {
+		return nil, err
+	}
+	return database.WithTenant(auditContext(), tenantID), nil
+}
+
+// usageError prints the usage of a command and returns errUsage
+func usageError(fs *flag.FlagSet) error {
+	fs.Usage()
+	return errUsage
+}
@@ -0,0 +1,73 @@
+// cli/migrate.go
+package cli
+
+import (
+	"fmt"
+	"inventory_system/database"
+	"strconv"
+	"time"
+)
+
+// runMigrate applies, reverts or lists the schema migrations
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate")
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return usageError(fs)
+	}
+
+	action, steps := args[0], 1
+	switch {
+	case action == "down" && len(args) == 2:
+		steps, err = strconv.Atoi(args[1])
+		if err != nil || steps <= 0 {
+			return fmt.Errorf("invalid number of steps %q", args[1])
+		}
+	case len(args) > 1:
+		return usageError(fs)
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	switch action {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		for _, migration := range applied {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return err
+	case "down":
+		reverted, err := database.MigrateDown(db, steps)
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("No migrations to revert")
+		}
+		return err
+	case "status":
+		states, err := database.GetMigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			applied := "pending"
+			if state.AppliedAt != nil {
+				applied = "applied " + state.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-30s %s\n", state.Version, state.Name, applied)
+		}
+		return nil
+	default:
+		return usageError(fs)
+	}
+}
@@ -0,0 +1,56 @@
+// cli/seed.go
+package cli
+
+import (
+	"fmt"
+	"inventory_system/database"
+	"log"
+	"time"
+)
+
+// runSeed fills an empty database with generated products, locations, stock
+// and order history
+func runSeed(args []string) error {
+	fs := newFlagSet("seed")
+	options := database.GenerateOptions{}
+	fs.IntVar(&options.Products, "products", 100, "number of products, spread across the categories")
+	fs.IntVar(&options.Locations, "locations", 4, "number of locations; a third of them are warehouses")
+	fs.IntVar(&options.Orders, "orders", 10000, "number of orders")
+	fs.IntVar(&options.Days, "days", 365, "days of order history")
+	end := fs.String("end", "", "day the history runs up to, as YYYY-MM-DD; today when empty")
+	fs.Int64Var(&options.Seed, "seed", 1, "random seed; the same seed and end day produce the same data")
+	fs.IntVar(&options.BatchSize, "batch-size", 1000, "rows written per INSERT statement")
+	tenant := tenantFlag(fs)
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageError(fs)
+	}
+
+	options.End = time.Now()
+	if *end != "" {
+		if options.End, err = time.Parse("2006-01-02", *end); err != nil {
+			return fmt.Errorf("invalid -end %q, use YYYY-MM-DD", *end)
+		}
+	}
+	if options.Orders >= 100000 {
+		options.Progress = func(orders int) { log.Printf("Wrote %d of %d orders", orders, options.Orders) }
+	}
+
+	db := database.Initialize(cfg)
+	ctx, err := tenantContext(db, *tenant)
+	if err != nil {
+		return err
+	}
+	started := time.Now()
+	result, err := database.GenerateSampleData(db.WithContext(ctx), options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Seeded %d products at %d locations, %d receipts and %d orders in %s\n",
+		result.Products, result.Locations, result.Receipts, result.Orders, time.Since(started).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,75 @@
+// cli/serve.go
+package cli
+
+import (
+	"context"
+	"inventory_system/database"
+	"inventory_system/events"
+	"inventory_system/jobs"
+	"inventory_system/reports"
+	"inventory_system/routes"
+	"inventory_system/webhooks"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// runServe runs the HTTP server and the background jobs
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	seed := fs.Bool("seed", false, "deprecated and ignored; run the seed command instead")
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageError(fs)
+	}
+
+	// The server no longer seeds an empty database
+	if *seed || cfg.Database.Seed {
+		log.Println("Warning: the seed setting (database.seed, SEED, -seed) is deprecated and ignored; run the seed command to fill an empty database")
+	}
+
+	// Create uploads directory if it doesn't exist
+	if _, err := os.Stat(cfg.Server.UploadDir); os.IsNotExist(err) {
+		os.MkdirAll(cfg.Server.UploadDir, 0755)
+	}
+
+	// Initialize database
+	db := database.Initialize(cfg)
+
+	// Reclassify products periodically (ABC/XYZ)
+	if interval := time.Duration(cfg.Classification.Interval); interval > 0 {
+		jobs.StartClassification(context.Background(), db, cfg.ReportLocation(), interval, cfg.Classification.WindowDays)
+	}
+
+	// Deliver scheduled reports when they fall due
+	if interval := time.Duration(cfg.Reports.SchedulerInterval); interval > 0 {
+		jobs.StartReportScheduler(context.Background(), db, reports.NewSinkConfig(cfg), cfg.ReportLocation(), interval)
+	}
+
+	// Publish the events recorded with each change
+	if interval := time.Duration(cfg.Outbox.RelayInterval); interval > 0 {
+		relay, err := events.NewRelay(cfg)
+		if err != nil {
+			return err
+		}
+		jobs.StartOutboxRelay(context.Background(), db, relay, interval)
+	}
+
+	// Send queued webhook deliveries and retry failed ones
+	if interval := time.Duration(cfg.Webhooks.DispatchInterval); interval > 0 {
+		jobs.StartWebhookDispatcher(context.Background(), db, webhooks.NewDispatcher(cfg), interval)
+	}
+
+	// Setup router
+	r := routes.SetupRouter(db, cfg)
+
+	// Start server
+	port := strconv.Itoa(cfg.Server.Port)
+
+	log.Printf("Server starting on port %s...", port)
+	return r.Run(":" + port)
+}
@@ -0,0 +1,46 @@
+// cli/stock.go
+package cli
+
+import (
+	"fmt"
+	"inventory_system/database"
+	"inventory_system/services"
+)
+
+// runRecomputeStock rebuilds the location-level stock from the bins and reports
+// every figure that had drifted
+func runRecomputeStock(args []string) error {
+	fs := newFlagSet("recompute-stock")
+	dryRun := fs.Bool("dry-run", false, "only report the drift, without correcting it")
+	tenant := tenantFlag(fs)
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageError(fs)
+	}
+
+	db := database.Initialize(cfg)
+	ctx, err := tenantContext(db, *tenant)
+	if err != nil {
+		return err
+	}
+	drifts, err := (&services.GormInventoryService{DB: db}).RecomputeStock(ctx, !*dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, drift := range drifts {
+		fmt.Printf("Product %d at %s: recorded %d, bins hold %d\n", drift.ProductID, drift.Location, drift.Recorded, drift.Actual)
+	}
+	switch {
+	case len(drifts) == 0:
+		fmt.Println("Stock matches the bins")
+	case *dryRun:
+		fmt.Printf("%d stock levels drifted; run without -dry-run to correct them\n", len(drifts))
+	default:
+		fmt.Printf("Corrected %d stock levels\n", len(drifts))
+	}
+	return nil
+}
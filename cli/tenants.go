@@ -0,0 +1,67 @@
+// cli/tenants.go
+package cli
+
+import (
+	"fmt"
+	"inventory_system/database"
+	"inventory_system/services"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runCreateTenant adds a tenant; its users are then added with create-user -tenant
+func runCreateTenant(args []string) error {
+	fs := newFlagSet("create-tenant")
+	categories := fs.String("categories", "", "comma-separated product categories; "+strings.Join(services.DefaultCategories, ", ")+" when empty")
+	locations := fs.String("locations", "", "comma-separated stock locations; any location is accepted when empty")
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError(fs)
+	}
+
+	db := database.Initialize(cfg)
+	tenant, err := (&services.GormTenantService{DB: db}).Create(services.TenantRequest{
+		Name:       args[0],
+		Categories: strings.Split(*categories, ","),
+		Locations:  strings.Split(*locations, ","),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created tenant %d (%s) with categories %s\n", tenant.ID, tenant.Name, strings.Join(tenant.Categories, ", "))
+	if len(tenant.Locations) > 0 {
+		fmt.Printf("Locations %s\n", strings.Join(tenant.Locations, ", "))
+	}
+	return nil
+}
+
+// runListTenants prints the tenants as a table
+func runListTenants(args []string) error {
+	fs := newFlagSet("list-tenants")
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usageError(fs)
+	}
+
+	db := database.Initialize(cfg)
+	tenants, err := (&services.GormTenantService{DB: db}).List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCATEGORIES\tLOCATIONS")
+	for _, tenant := range tenants {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", tenant.ID, tenant.Name,
+			strings.Join(tenant.Categories, ","), orDash(strings.Join(tenant.Locations, ",")))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,392 @@
+// cli/transfer.go
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inventory_system/database"
+	"inventory_system/models"
+	"inventory_system/services"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Columns of the exported and imported files, in CSV order
+var (
+	productColumns   = []string{"id", "name", "description", "category", "price", "standard_cost"}
+	inventoryColumns = []string{"product_id", "location", "quantity"}
+	orderColumns     = []string{"order_id", "product_id", "location", "quantity", "order_date", "total_price", "cost_of_goods"}
+)
+
+// fields is one imported or exported record keyed by column name
+type fields map[string]string
+
+// runExport writes products, inventory or orders as CSV or JSON
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	format := fs.String("format", "csv", "output format, csv or json")
+	output := fs.String("output", "", "file to write; standard output when empty")
+	tenant := tenantFlag(fs)
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 || (*format != "csv" && *format != "json") {
+		return usageError(fs)
+	}
+	// Keep query logging out of the exported data
+	if *output == "" {
+		cfg.Log.Level = "silent"
+	}
+
+	db := database.Initialize(cfg)
+	ctx, err := tenantContext(db, *tenant)
+	if err != nil {
+		return err
+	}
+	var columns []string
+	var records []fields
+
+	switch args[0] {
+	case "products":
+		columns = productColumns
+		products, err := (&services.GormProductService{DB: db}).List(ctx, services.ProductFilter{})
+		if err != nil {
+			return err
+		}
+		for _, p := range products {
+			records = append(records, fields{
+				"id":            strconv.FormatUint(uint64(p.ID), 10),
+				"name":          p.Name,
+				"description":   p.Description,
+				"category":      p.Category,
+				"price":         strconv.FormatFloat(p.Price, 'f', 2, 64),
+				"standard_cost": strconv.FormatFloat(p.StandardCost, 'f', -1, 64),
+			})
+		}
+	case "inventory":
+		columns = inventoryColumns
+		inventories, err := (&services.GormInventoryService{DB: db}).List(ctx, services.InventoryFilter{})
+		if err != nil {
+			return err
+		}
+		for _, i := range inventories {
+			records = append(records, fields{
+				"product_id": strconv.FormatUint(uint64(i.ProductID), 10),
+				"location":   i.Location,
+				"quantity":   strconv.Itoa(i.Quantity),
+			})
+		}
+	case "orders":
+		columns = orderColumns
+		orders, err := (&services.GormOrderService{DB: db}).List(ctx)
+		if err != nil {
+			return err
+		}
+		for _, o := range orders {
+			records = append(records, fields{
+				"order_id":      strconv.FormatUint(uint64(o.OrderID), 10),
+				"product_id":    strconv.FormatUint(uint64(o.ProductID), 10),
+				"location":      o.Location,
+				"quantity":      strconv.Itoa(o.Quantity),
+				"order_date":    o.OrderDate.Format(time.RFC3339),
+				"total_price":   strconv.FormatFloat(o.TotalPrice, 'f', 2, 64),
+				"cost_of_goods": strconv.FormatFloat(o.CostOfGoods, 'f', 2, 64),
+			})
+		}
+	default:
+		return usageError(fs)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := writeRecords(w, *format, columns, records); err != nil {
+		return err
+	}
+	if *output != "" {
+		fmt.Printf("Exported %d %s to %s\n", len(records), args[0], *output)
+	}
+	return nil
+}
+
+// runImport creates or updates products, or sets stock levels, from a file
+func runImport(args []string) error {
+	fs := newFlagSet("import")
+	format := fs.String("format", "", "input format, csv or json; taken from the file extension when empty")
+	tenant := tenantFlag(fs)
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usageError(fs)
+	}
+	kind, path := args[0], args[1]
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("cannot tell the format of %s; use -format csv or -format json", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	records, err := readRecords(file, *format)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	db := database.Initialize(cfg)
+	ctx, err := tenantContext(db, *tenant)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "products":
+		return importProducts(ctx, &services.GormProductService{DB: db}, records)
+	case "inventory":
+		// Imported stock levels are not held for approval
+		return importInventory(ctx, &services.GormInventoryService{DB: db}, records, path)
+	default:
+		return usageError(fs)
+	}
+}
+
+// importProducts updates the products whose id exists and creates the rest
+func importProducts(ctx context.Context, products services.ProductService, records []fields) error {
+	var created, updated int
+	for n, record := range records {
+		id, err := record.uint("id")
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+		price, err := record.float("price")
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+		standardCost, err := record.float("standard_cost")
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+
+		update := services.ProductUpdate{
+			Name:         record["name"],
+			Description:  record["description"],
+			Price:        price,
+			Category:     record["category"],
+			StandardCost: standardCost,
+		}
+		if id != 0 {
+			_, err := products.Update(ctx, id, update)
+			if err == nil {
+				updated++
+				continue
+			}
+			if !errors.Is(err, services.ErrProductNotFound) {
+				return fmt.Errorf("record %d: %w", n+1, err)
+			}
+		}
+
+		product := models.Product{
+			Name:         update.Name,
+			Description:  update.Description,
+			Price:        update.Price,
+			Category:     update.Category,
+			StandardCost: update.StandardCost,
+		}
+		if err := products.Create(ctx, &product); err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+		created++
+	}
+
+	fmt.Printf("Imported products: %d created, %d updated\n", created, updated)
+	return nil
+}
+
+// importInventory adjusts the stock of each product and location to the
+// quantity in the file
+func importInventory(ctx context.Context, inventory services.InventoryService, records []fields, source string) error {
+	var changed int
+	for n, record := range records {
+		productID, err := record.uint("product_id")
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+		quantity, err := record.int("quantity")
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+		location := record["location"]
+		if productID == 0 || location == "" || quantity < 0 {
+			return fmt.Errorf("record %d: product_id, location and a quantity of at least 0 are required", n+1)
+		}
+
+		current, err := inventory.List(ctx, services.InventoryFilter{ProductID: productID, Location: location})
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+		difference := quantity
+		if len(current) > 0 {
+			difference -= current[0].Quantity
+		}
+		if difference == 0 {
+			continue
+		}
+
+		request := services.AdjustmentRequest{
+			ProductID: productID,
+			Location:  location,
+			Action:    "add",
+			Quantity:  difference,
+			Reason:    models.ReasonCorrection,
+			Note:      "Imported from " + filepath.Base(source),
+		}
+		if difference < 0 {
+			request.Action, request.Quantity = "remove", -difference
+		}
+		if _, err := inventory.Adjust(ctx, request); err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+		changed++
+	}
+
+	fmt.Printf("Imported inventory: %d of %d stock levels changed\n", changed, len(records))
+	return nil
+}
+
+// writeRecords writes records with a header row as CSV, or as a JSON array
+// with numbers left as numbers
+func writeRecords(w io.Writer, format string, columns []string, records []fields) error {
+	if format == "csv" {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		for _, record := range records {
+			row := make([]string, len(columns))
+			for i, column := range columns {
+				row[i] = record[column]
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	objects := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		objects[i] = make(map[string]interface{}, len(record))
+		for column, value := range record {
+			if number, err := strconv.ParseFloat(value, 64); err == nil && !isTextColumn(column) {
+				objects[i][column] = json.Number(strconv.FormatFloat(number, 'f', -1, 64))
+			} else {
+				objects[i][column] = value
+			}
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(objects)
+}
+
+// readRecords reads a CSV file with a header row, or a JSON array of objects
+func readRecords(r io.Reader, format string) ([]fields, error) {
+	var records []fields
+	if format == "csv" {
+		rows, err := csv.NewReader(r).ReadAll()
+		if err != nil || len(rows) == 0 {
+			return nil, err
+		}
+		header := rows[0]
+		for _, row := range rows[1:] {
+			record := make(fields, len(header))
+			for i, column := range header {
+				record[strings.TrimSpace(column)] = strings.TrimSpace(row[i])
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	}
+
+	var objects []map[string]interface{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		record := make(fields, len(object))
+		for column, value := range object {
+			if value != nil {
+				record[column] = fmt.Sprint(value)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// isTextColumn reports whether a column holds text even when it looks like a number
+func isTextColumn(column string) bool {
+	switch column {
+	case "name", "description", "category", "location", "order_date":
+		return true
+	}
+	return false
+}
+
+// uint parses a column as an ID; an empty column is zero
+func (f fields) uint(column string) (uint, error) {
+	if f[column] == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(f[column], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is not a valid ID", column, f[column])
+	}
+	return uint(value), nil
+}
+
+// int parses a column as a whole number; an empty column is zero
+func (f fields) int(column string) (int, error) {
+	if f[column] == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(f[column])
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is not a whole number", column, f[column])
+	}
+	return value, nil
+}
+
+// float parses a column as a number; an empty column is zero
+func (f fields) float(column string) (float64, error) {
+	if f[column] == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(f[column], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is not a number", column, f[column])
+	}
+	return value, nil
+}
@@ -0,0 +1,63 @@
+// cli/users.go
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"inventory_system/auth"
+	"inventory_system/database"
+	"inventory_system/services"
+	"os"
+	"strings"
+)
+
+// runCreateUser adds a user who can sign in to the API. The password is read
+// from standard input unless it is given with -password.
+func runCreateUser(args []string) error {
+	fs := newFlagSet("create-user")
+	password := fs.String("password", "", "the user's password; read from standard input when empty")
+	role := fs.String("role", auth.RoleClerk, "the user's role: "+strings.Join(auth.RoleNames(), ", "))
+	locations := fs.String("locations", "", "comma-separated locations the user may change stock at; all when empty")
+	tenant := tenantFlag(fs)
+	cfg, args, err := load(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError(fs)
+	}
+	if !auth.IsValidRole(*role) {
+		return services.ErrInvalidRole
+	}
+
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("reading password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	db := database.Initialize(cfg)
+	tenantID, err := findTenant(db, *tenant)
+	if err != nil {
+		return err
+	}
+	user, err := (&services.GormUserService{DB: db}).Create(services.UserRequest{
+		TenantID:  tenantID,
+		Username:  args[0],
+		Password:  *password,
+		Role:      *role,
+		Locations: strings.Split(*locations, ","),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created %s %d (%s)\n", user.Role, user.ID, user.Username)
+	if len(user.Locations) > 0 {
+		fmt.Printf("Limited to %s\n", strings.Join(user.Locations, ", "))
+	}
+	return nil
+}
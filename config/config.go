@@ -0,0 +1,317 @@
+// config/config.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds every setting of the service. Values are loaded from defaults,
+// then a YAML or TOML file, then environment variables, then command-line flags,
+// each source overriding the ones before it.
+type Config struct {
+	Server         ServerConfig         `yaml:"server" toml:"server"`
+	Database       DatabaseConfig       `yaml:"database" toml:"database"`
+	Log            LogConfig            `yaml:"log" toml:"log"`
+	Inventory      InventoryConfig      `yaml:"inventory" toml:"inventory"`
+	Reports        ReportsConfig        `yaml:"reports" toml:"reports"`
+	SMTP           SMTPConfig           `yaml:"smtp" toml:"smtp"`
+	Classification ClassificationConfig `yaml:"classification" toml:"classification"`
+	Auth           AuthConfig           `yaml:"auth" toml:"auth"`
+	Webhooks       WebhooksConfig       `yaml:"webhooks" toml:"webhooks"`
+	Outbox         OutboxConfig         `yaml:"outbox" toml:"outbox"`
+}
+
+// ServerConfig holds the HTTP server settings
+type ServerConfig struct {
+	Port      int    `yaml:"port" toml:"port" env:"PORT" flag:"port"`
+	UploadDir string `yaml:"upload_dir" toml:"upload_dir" env:"UPLOAD_DIR" flag:"upload-dir"`
+}
+
+// Supported database drivers
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// DatabaseConfig holds the database driver, the parts of its DSN and the
+// connection pool sizes
+type DatabaseConfig struct {
+	Driver string `yaml:"driver" toml:"driver" env:"DB_DRIVER" flag:"db-driver"`
+	// Path is the database file used by the SQLite driver
+	Path string `yaml:"path" toml:"path" env:"DB_PATH" flag:"db-path"`
+	Host string `yaml:"host" toml:"host" env:"DB_HOST" flag:"db-host"`
+	// Port defaults to the driver's standard port when zero
+	Port     int    `yaml:"port" toml:"port" env:"DB_PORT" flag:"db-port"`
+	User     string `yaml:"user" toml:"user" env:"DB_USER" flag:"db-user"`
+	Password string `yaml:"password" toml:"password" env:"DB_PASSWORD"`
+	// PasswordFile is read for the password when set, for Docker and systemd secrets
+	PasswordFile string `yaml:"password_file" toml:"password_file" env:"DB_PASSWORD_FILE" flag:"db-password-file"`
+	Name         string `yaml:"name" toml:"name" env:"DB_NAME" flag:"db-name"`
+	// Params are appended to the DSN; each driver has its own defaults when empty
+	Params          string   `yaml:"params" toml:"params" env:"DB_PARAMS"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" flag:"db-max-idle-conns"`
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" flag:"db-max-open-conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	// Seed is deprecated and ignored: the seed command fills an empty database.
+	// It is still read so that existing config files load; serve warns when it is set.
+	Seed bool `yaml:"seed" toml:"seed" env:"SEED"`
+}
+
+// LogConfig holds the logging settings
+type LogConfig struct {
+	// Level is the database log level: silent, error, warn or info
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL" flag:"log-level"`
+}
+
+// InventoryConfig holds the stock valuation and adjustment approval settings
+type InventoryConfig struct {
+	CostingMethod    string  `yaml:"costing_method" toml:"costing_method" env:"COSTING_METHOD"`
+	ApprovalQuantity int     `yaml:"approval_quantity" toml:"approval_quantity" env:"ADJUSTMENT_APPROVAL_QUANTITY"`
+	ApprovalValue    float64 `yaml:"approval_value" toml:"approval_value" env:"ADJUSTMENT_APPROVAL_VALUE"`
+}
+
+// ReportsConfig holds the reporting time zone and the report scheduler settings
+type ReportsConfig struct {
+	TimeZone          string   `yaml:"time_zone" toml:"time_zone" env:"REPORT_TIMEZONE"`
+	SchedulerInterval Duration `yaml:"scheduler_interval" toml:"scheduler_interval" env:"REPORT_SCHEDULER_INTERVAL"`
+	OutboxDir         string   `yaml:"outbox_dir" toml:"outbox_dir" env:"REPORT_OUTBOX_DIR"`
+}
+
+// SMTPConfig holds the mail server scheduled reports are sent through
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" toml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" toml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" toml:"password" env:"SMTP_PASSWORD"`
+	From     string `yaml:"from" toml:"from" env:"SMTP_FROM"`
+}
+
+// ClassificationConfig holds the periodic ABC/XYZ classification settings
+type ClassificationConfig struct {
+	Interval   Duration `yaml:"interval" toml:"interval" env:"CLASSIFICATION_INTERVAL"`
+	WindowDays int      `yaml:"window_days" toml:"window_days" env:"CLASSIFICATION_WINDOW_DAYS"`
+}
+
+// AuthConfig holds the key that signs access tokens and how long tokens last
+type AuthConfig struct {
+	// Secret signs the tokens; when empty a random one is used until the server restarts
+	Secret     string   `yaml:"secret" toml:"secret" env:"JWT_SECRET"`
+	AccessTTL  Duration `yaml:"access_ttl" toml:"access_ttl" env:"JWT_ACCESS_TTL"`
+	RefreshTTL Duration `yaml:"refresh_ttl" toml:"refresh_ttl" env:"JWT_REFRESH_TTL"`
+}
+
+// WebhooksConfig holds how often queued webhook deliveries are sent and how
+// failed ones are retried
+type WebhooksConfig struct {
+	DispatchInterval Duration `yaml:"dispatch_interval" toml:"dispatch_interval" env:"WEBHOOK_DISPATCH_INTERVAL"`
+	// MaxAttempts is how many times a delivery is tried before it is marked failed
+	MaxAttempts int `yaml:"max_attempts" toml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	// RetryDelay is the wait after the first failed attempt; it doubles after each
+	RetryDelay Duration `yaml:"retry_delay" toml:"retry_delay" env:"WEBHOOK_RETRY_DELAY"`
+	Timeout    Duration `yaml:"timeout" toml:"timeout" env:"WEBHOOK_TIMEOUT"`
+}
+
+// OutboxConfig holds how often the outbox relay runs and where it publishes events
+type OutboxConfig struct {
+	RelayInterval Duration `yaml:"relay_interval" toml:"relay_interval" env:"OUTBOX_RELAY_INTERVAL"`
+	// RetryDelay is the wait after the first failed attempt; it doubles after each
+	RetryDelay Duration `yaml:"retry_delay" toml:"retry_delay" env:"OUTBOX_RETRY_DELAY"`
+	// Sinks is a comma-separated list of webhook, file and redis
+	Sinks string `yaml:"sinks" toml:"sinks" env:"OUTBOX_SINKS"`
+	// File is the JSON lines file of the file sink
+	File        string `yaml:"file" toml:"file" env:"OUTBOX_FILE"`
+	RedisAddr   string `yaml:"redis_addr" toml:"redis_addr" env:"OUTBOX_REDIS_ADDR"`
+	RedisStream string `yaml:"redis_stream" toml:"redis_stream" env:"OUTBOX_REDIS_STREAM"`
+}
+
+// MinSecretLength is the shortest token signing secret accepted
+const MinSecretLength = 32
+
+// Duration is a time.Duration written as a string such as 90s or 24h
+type Duration time.Duration
+
+// UnmarshalText parses a duration string
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText formats the duration as a string
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Default returns the settings used when no other source sets them
+func Default() Config {
+	return Config{
+		Server: ServerConfig{Port: 8080, UploadDir: "uploads"},
+		Database: DatabaseConfig{
+			Driver:          DriverMySQL,
+			Path:            "inventory_system.db",
+			Host:            "localhost",
+			User:            "root",
+			Name:            "inventory_system",
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: Duration(time.Hour),
+		},
+		Log:            LogConfig{Level: "info"},
+		Inventory:      InventoryConfig{CostingMethod: "average"},
+		Reports:        ReportsConfig{SchedulerInterval: Duration(time.Minute), OutboxDir: "outbox"},
+		SMTP:           SMTPConfig{Host: "localhost", Port: 25, From: "reports@localhost"},
+		Classification: ClassificationConfig{Interval: Duration(24 * time.Hour), WindowDays: 365},
+		Auth:           AuthConfig{AccessTTL: Duration(15 * time.Minute), RefreshTTL: Duration(7 * 24 * time.Hour)},
+		Webhooks: WebhooksConfig{
+			DispatchInterval: Duration(10 * time.Second),
+			MaxAttempts:      8,
+			RetryDelay:       Duration(30 * time.Second),
+			Timeout:          Duration(10 * time.Second),
+		},
+		Outbox: OutboxConfig{
+			RelayInterval: Duration(time.Second),
+			RetryDelay:    Duration(5 * time.Second),
+			Sinks:         "webhook",
+			File:          "events.jsonl",
+			RedisAddr:     "localhost:6379",
+			RedisStream:   "inventory-events",
+		},
+	}
+}
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server port %d is out of range", c.Server.Port)
+	check(c.Server.UploadDir != "", "upload dir must be set")
+
+	check(oneOf(c.Database.Driver, DriverMySQL, DriverPostgres, DriverSQLite),
+		"database driver must be one of %s, %s or %s", DriverMySQL, DriverPostgres, DriverSQLite)
+	if c.Database.Driver == DriverSQLite {
+		check(c.Database.Path != "", "database path must be set for sqlite")
+	} else {
+		check(c.Database.Host != "", "database host must be set")
+		check(c.Database.Port >= 0 && c.Database.Port < 65536, "database port %d is out of range", c.Database.Port)
+		check(c.Database.User != "", "database user must be set")
+		check(c.Database.Name != "", "database name must be set")
+	}
+	check(c.Database.MaxOpenConns > 0, "database max open connections must be positive")
+	check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database max idle connections must be between 0 and max open connections (%d)", c.Database.MaxOpenConns)
+	check(c.Database.ConnMaxLifetime >= 0, "database connection max lifetime must not be negative")
+
+	check(oneOf(c.Log.Level, "silent", "error", "warn", "info"), "log level must be one of silent, error, warn or info")
+
+	check(oneOf(c.Inventory.CostingMethod, "fifo", "average", "standard"), "costing method must be one of fifo, average or standard")
+	check(c.Inventory.ApprovalQuantity >= 0, "adjustment approval quantity must not be negative")
+	check(c.Inventory.ApprovalValue >= 0, "adjustment approval value must not be negative")
+
+	if c.Reports.TimeZone != "" {
+		_, err := time.LoadLocation(c.Reports.TimeZone)
+		check(err == nil, "unknown report time zone %q", c.Reports.TimeZone)
+	}
+	check(c.Reports.SchedulerInterval >= 0, "report scheduler interval must not be negative")
+	check(c.Reports.OutboxDir != "", "report outbox dir must be set")
+
+	check(c.SMTP.Port > 0 && c.SMTP.Port < 65536, "smtp port %d is out of range", c.SMTP.Port)
+
+	check(c.Classification.Interval >= 0, "classification interval must not be negative")
+	check(c.Classification.WindowDays > 0, "classification window days must be positive")
+
+	check(c.Auth.Secret == "" || len(c.Auth.Secret) >= MinSecretLength, "auth secret must be at least %d characters", MinSecretLength)
+	check(c.Auth.AccessTTL > 0, "auth access token lifetime must be positive")
+	check(c.Auth.RefreshTTL >= c.Auth.AccessTTL, "auth refresh token lifetime must not be shorter than the access token lifetime")
+
+	check(c.Webhooks.DispatchInterval >= 0, "webhook dispatch interval must not be negative")
+	check(c.Webhooks.MaxAttempts > 0, "webhook max attempts must be positive")
+	check(c.Webhooks.RetryDelay > 0, "webhook retry delay must be positive")
+	check(c.Webhooks.Timeout > 0, "webhook timeout must be positive")
+
+	check(c.Outbox.RelayInterval >= 0, "outbox relay interval must not be negative")
+	check(c.Outbox.RetryDelay > 0, "outbox retry delay must be positive")
+	for _, sink := range strings.Split(c.Outbox.Sinks, ",") {
+		sink = strings.TrimSpace(sink)
+		check(sink == "" || oneOf(sink, "webhook", "file", "redis"), "outbox sink %q must be one of webhook, file or redis", sink)
+		check(sink != "file" || c.Outbox.File != "", "outbox file must be set for the file sink")
+		check(sink != "redis" || (c.Outbox.RedisAddr != "" && c.Outbox.RedisStream != ""), "outbox redis address and stream must be set for the redis sink")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ReportLocation returns the reporting time zone, or local time when none is set
+func (c *Config) ReportLocation() *time.Location {
+	if c.Reports.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Reports.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// DSN builds the data source name for the configured driver
+func (d DatabaseConfig) DSN() string {
+	switch d.Driver {
+	case DriverPostgres:
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(d.User, d.Password),
+			Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.portOr(5432))),
+			Path:     d.Name,
+			RawQuery: d.paramsOr("sslmode=disable"),
+		}
+		return dsn.String()
+	case DriverSQLite:
+		return d.Path + "?" + d.paramsOr("_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	default:
+		return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s", d.User, d.Password,
+			net.JoinHostPort(d.Host, strconv.Itoa(d.portOr(3306))), d.Name, d.paramsOr("charset=utf8mb4&parseTime=True&loc=Local"))
+	}
+}
+
+// portOr returns the configured port or the driver's standard port
+func (d DatabaseConfig) portOr(fallback int) int {
+	if d.Port == 0 {
+		return fallback
+	}
+	return d.Port
+}
+
+// paramsOr returns the configured DSN parameters or the driver's defaults
+func (d DatabaseConfig) paramsOr(fallback string) string {
+	if d.Params == "" {
+		return fallback
+	}
+	return d.Params
+}
+
+// oneOf reports whether value is one of the allowed values
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
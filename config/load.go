@@ -0,0 +1,203 @@
+// config/load.go
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Load builds the configuration from defaults, the file named by -config or
+// CONFIG_FILE, environment variables and the command-line flags in args, in
+// that order of precedence, and validates the result
+func Load(args []string) (*Config, error) {
+	return LoadFlags(flag.NewFlagSet("inventory_system", flag.ContinueOnError), args)
+}
+
+// LoadFlags is Load with the configuration flags added to fs, so that a command
+// can parse its own flags alongside them
+func LoadFlags(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+
+	path := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file (env CONFIG_FILE)")
+	flags := registerFlags(fs, &cfg)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *path != "" {
+		if err := loadFile(*path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadEnv(&cfg); err != nil {
+		return nil, err
+	}
+
+	// Only flags given on the command line override the other sources
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		if value, ok := flags[f.Name]; ok && flagErr == nil {
+			if err := setField(value.field, value.raw); err != nil {
+				flagErr = fmt.Errorf("flag -%s: %w", f.Name, err)
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if cfg.Database.PasswordFile != "" {
+		password, err := os.ReadFile(cfg.Database.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading database password file: %w", err)
+		}
+		cfg.Database.Password = strings.TrimSpace(string(password))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// loadFile reads a YAML (.yaml, .yml) or TOML (.toml) file over cfg, rejecting
+// unknown keys so that typos do not go unnoticed
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(cfg)
+		// An empty file leaves the defaults in place
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(cfg)
+	default:
+		return fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv applies every environment variable named in an env tag
+func loadEnv(cfg *Config) error {
+	var err error
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		name := field.Tag.Get("env")
+		if name == "" || err != nil {
+			return
+		}
+		if raw, ok := os.LookupEnv(name); ok {
+			if setErr := setField(value, raw); setErr != nil {
+				err = fmt.Errorf("environment variable %s: %w", name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// fieldFlag collects the raw value of a command-line flag for one config field
+type fieldFlag struct {
+	field reflect.Value
+	raw   string
+}
+
+func (f *fieldFlag) String() string { return f.raw }
+
+func (f *fieldFlag) Set(raw string) error {
+	f.raw = raw
+	return nil
+}
+
+// IsBoolFlag lets boolean fields be set with a bare -flag
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.field.IsValid() && f.field.Kind() == reflect.Bool
+}
+
+// registerFlags defines a flag for every config field with a flag tag
+func registerFlags(fs *flag.FlagSet, cfg *Config) map[string]*fieldFlag {
+	flags := make(map[string]*fieldFlag)
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		name := field.Tag.Get("flag")
+		if name == "" {
+			return
+		}
+		f := &fieldFlag{field: value}
+		flags[name] = f
+		usage := fmt.Sprintf("overrides %s", field.Name)
+		if env := field.Tag.Get("env"); env != "" {
+			usage = fmt.Sprintf("overrides env %s", env)
+		}
+		fs.Var(f, name, usage)
+	})
+	return flags
+}
+
+// walk calls fn for every leaf field of the nested config structs
+func walk(v reflect.Value, fn func(reflect.StructField, reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if value.Kind() == reflect.Struct {
+			walk(value, fn)
+			continue
+		}
+		fn(field, value)
+	}
+}
+
+// setField parses raw into a config field according to its type
+func setField(value reflect.Value, raw string) error {
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		value.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		value.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		value.SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+	return nil
+}
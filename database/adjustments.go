@@ -0,0 +1,67 @@
+// database/adjustments.go
+package database
+
+import (
+	"fmt"
+	"inventory_system/models"
+
+	"gorm.io/gorm"
+)
+
+// ApplyAdjustment changes the bin-level stock described by a manual adjustment,
+// records the movement with its reason code and returns the new location total.
+// Additions without a bin land in receiving; removals without a bin are picked
+// across the location.
+func ApplyAdjustment(tx *gorm.DB, adjustment *models.Adjustment) (models.Inventory, error) {
+	var bin *models.Bin
+	if adjustment.BinID != nil {
+		bin = &models.Bin{}
+		if err := tx.First(bin, *adjustment.BinID).Error; err != nil {
+			return models.Inventory{}, err
+		}
+	}
+
+	movement := models.StockMovement{
+		ProductID: adjustment.ProductID,
+		Location:  adjustment.Location,
+		Reason:    adjustment.Reason,
+		Reference: fmt.Sprintf("adjustment:%d", adjustment.ID),
+	}
+	if adjustment.UnitCost != nil {
+		movement.UnitCost = *adjustment.UnitCost
+	}
+
+	var err error
+	if adjustment.Action == "add" {
+		if bin == nil {
+			receiving, err := GetReceivingBin(tx, adjustment.Location)
+			if err != nil {
+				return models.Inventory{}, err
+			}
+			bin = &receiving
+		}
+		movement.Type = models.MovementReceipt
+		movement.Quantity = adjustment.Quantity
+		err = AddToBin(tx, adjustment.ProductID, *bin, adjustment.Quantity)
+	} else { // "remove"
+		movement.Type = models.MovementIssue
+		movement.Quantity = -adjustment.Quantity
+		if bin != nil {
+			err = RemoveFromBin(tx, adjustment.ProductID, *bin, adjustment.Quantity)
+		} else {
+			err = PickFromLocation(tx, adjustment.ProductID, adjustment.Location, adjustment.Quantity)
+		}
+	}
+	if err != nil {
+		return models.Inventory{}, err
+	}
+
+	if bin != nil {
+		movement.BinID = &bin.ID
+	}
+	if err := RecordMovement(tx, &movement); err != nil {
+		return models.Inventory{}, err
+	}
+
+	return SyncInventory(tx, adjustment.ProductID, adjustment.Location)
+}
@@ -0,0 +1,176 @@
+// database/analytics.go
+package database
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StockAnalyticsRow holds the sales velocity and turnover of a product at a location
+type StockAnalyticsRow struct {
+	ProductID          uint       `json:"product_id"`
+	Name               string     `json:"name"`
+	Category           string     `json:"category"`
+	Location           string     `json:"location"`
+	OnHand             int        `json:"on_hand"`
+	OpeningQuantity    int        `json:"opening_quantity"`
+	AverageQuantity    float64    `json:"average_quantity"`
+	UnitsSold          int        `json:"units_sold"`
+	CostOfGoods        float64    `json:"cost_of_goods"`
+	DailyVelocity      float64    `json:"daily_velocity"`
+	Turnover           float64    `json:"turnover"`
+	AnnualizedTurnover float64    `json:"annualized_turnover"`
+	DaysOfSupply       *float64   `json:"days_of_supply"`
+	LastSoldAt         *time.Time `json:"last_sold_at"`
+}
+
+// stockKey identifies a product at a location
+type stockKey struct {
+	ProductID uint
+	Location  string
+}
+
+// GetStockAnalytics computes, for every product and location holding stock or
+// selling in the window, the units sold over the last days, inventory turnover
+// and days of supply at the recent sales velocity. Average inventory is taken
+// as the mean of the opening and closing quantity of the window, where the
+// opening quantity is rebuilt from the movement ledger.
+func GetStockAnalytics(db *gorm.DB, days int, now time.Time) ([]StockAnalyticsRow, error) {
+	since := now.AddDate(0, 0, -days)
+	rows := make(map[stockKey]*StockAnalyticsRow)
+
+	var onHand []struct {
+		ProductID uint
+		Name      string
+		Category  string
+		Location  string
+		Quantity  int
+	}
+	if err := db.Table("inventories").
+		Select("inventories.product_id, products.name, products.category, inventories.location, inventories.quantity").
+		Joins("JOIN products ON inventories.product_id = products.id").
+		Scan(&onHand).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range onHand {
+		rows[stockKey{item.ProductID, item.Location}] = &StockAnalyticsRow{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Category:  item.Category,
+			Location:  item.Location,
+			OnHand:    item.Quantity,
+		}
+	}
+
+	var sales []struct {
+		ProductID   uint
+		Location    string
+		UnitsSold   int
+		CostOfGoods float64
+	}
+	if err := db.Table("orders").
+		Select("product_id, location, SUM(quantity) as units_sold, SUM(cost_of_goods) as cost_of_goods").
+		Where("order_date >= ?", since).
+		Group("product_id, location").
+		Scan(&sales).Error; err != nil {
+		return nil, err
+	}
+	for _, sale := range sales {
+		if row, ok := rows[stockKey{sale.ProductID, sale.Location}]; ok {
+			row.UnitsSold = sale.UnitsSold
+			row.CostOfGoods = sale.CostOfGoods
+		}
+	}
+
+	var lastSales []struct {
+		ProductID  uint
+		Location   string
+		LastSoldAt aggregateTime
+	}
+	if err := db.Table("orders").
+		Select("product_id, location, MAX(order_date) as last_sold_at").
+		Group("product_id, location").
+		Scan(&lastSales).Error; err != nil {
+		return nil, err
+	}
+	for _, sale := range lastSales {
+		if row, ok := rows[stockKey{sale.ProductID, sale.Location}]; ok {
+			lastSoldAt := sale.LastSoldAt.Time
+			row.LastSoldAt = &lastSoldAt
+		}
+	}
+
+	var movements []struct {
+		ProductID uint
+		Location  string
+		Net       int
+	}
+	if err := db.Table("stock_movements").
+		Select("product_id, location, SUM(quantity) as net").
+		Where("created_at >= ?", since).
+		Group("product_id, location").
+		Scan(&movements).Error; err != nil {
+		return nil, err
+	}
+	net := make(map[stockKey]int, len(movements))
+	for _, movement := range movements {
+		net[stockKey{movement.ProductID, movement.Location}] = movement.Net
+	}
+
+	results := make([]StockAnalyticsRow, 0, len(rows))
+	for key, row := range rows {
+		row.OpeningQuantity = row.OnHand - net[key]
+		if row.OpeningQuantity < 0 {
+			row.OpeningQuantity = 0
+		}
+		row.AverageQuantity = float64(row.OpeningQuantity+row.OnHand) / 2
+		row.DailyVelocity = float64(row.UnitsSold) / float64(days)
+
+		if row.AverageQuantity > 0 {
+			row.Turnover = float64(row.UnitsSold) / row.AverageQuantity
+			row.AnnualizedTurnover = row.Turnover * 365 / float64(days)
+		}
+		if row.DailyVelocity > 0 {
+			supply := float64(row.OnHand) / row.DailyVelocity
+			row.DaysOfSupply = &supply
+		}
+
+		results = append(results, *row)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ProductID != results[j].ProductID {
+			return results[i].ProductID < results[j].ProductID
+		}
+		return results[i].Location < results[j].Location
+	})
+	return results, nil
+}
+
+// GetDeadStock returns products holding stock at a location that have not sold
+// there in the last days, longest unsold first
+func GetDeadStock(db *gorm.DB, days int, now time.Time) ([]StockAnalyticsRow, error) {
+	rows, err := GetStockAnalytics(db, days, now)
+	if err != nil {
+		return nil, err
+	}
+
+	dead := []StockAnalyticsRow{}
+	for _, row := range rows {
+		if row.OnHand > 0 && row.UnitsSold == 0 {
+			dead = append(dead, row)
+		}
+	}
+
+	// Never-sold items come first, then the oldest last sale
+	sort.SliceStable(dead, func(i, j int) bool {
+		a, b := dead[i].LastSoldAt, dead[j].LastSoldAt
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+	return dead, nil
+}
@@ -0,0 +1,158 @@
+// database/audit.go
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"inventory_system/models"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SystemActor is recorded for writes made outside a request, such as by jobs
+const SystemActor = "system"
+
+type (
+	actorKey     struct{}
+	requestIDKey struct{}
+)
+
+// WithActor returns a context whose database writes are audited as actor's
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// WithRequestID returns a context whose database writes are audited under a request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// auditIgnored are fields that change on every write or are covered by the
+// entry itself, plus nested records, which are audited on their own
+var auditIgnored = map[string]bool{"created_at": true, "updated_at": true, "product": true}
+
+// RecordAudit writes an audit entry for a change to an entity in the same
+// transaction as the change. The actor and request ID come from the context
+// of tx. A nil before records a create and a nil after a delete; updates that
+// change nothing are not recorded.
+func RecordAudit(tx *gorm.DB, entity, entityID string, before, after interface{}) error {
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	entry := models.AuditEntry{
+		Actor:     SystemActor,
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    models.AuditUpdate,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}
+	switch {
+	case before == nil:
+		entry.Action = models.AuditCreate
+	case after == nil:
+		entry.Action = models.AuditDelete
+	}
+	if ctx := tx.Statement.Context; ctx != nil {
+		if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+			entry.Actor = actor
+		}
+		entry.RequestID, _ = ctx.Value(requestIDKey{}).(string)
+	}
+	return tx.Create(&entry).Error
+}
+
+// AuditFilter narrows an audit listing; empty fields match everything
+type AuditFilter struct {
+	Entity    string
+	EntityID  string
+	Actor     string
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+}
+
+// GetAuditEntries returns audit entries matching filter, newest first
+func GetAuditEntries(db *gorm.DB, filter AuditFilter) ([]models.AuditEntry, error) {
+	entries := []models.AuditEntry{}
+
+	if filter.Entity != "" {
+		db = db.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		db = db.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		db = db.Where("actor = ?", filter.Actor)
+	}
+	if filter.RequestID != "" {
+		db = db.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		db = db.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		db = db.Where("created_at < ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+
+	err := db.Order("created_at DESC, id DESC").Find(&entries).Error
+	return entries, err
+}
+
+// auditDiff compares the JSON fields of two versions of a record
+func auditDiff(before, after interface{}) (map[string]models.AuditChange, error) {
+	old, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]models.AuditChange)
+	for field, value := range current {
+		if previous, ok := old[field]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[field] = models.AuditChange{Before: old[field], After: value}
+		}
+	}
+	for field, value := range old {
+		if _, ok := current[field]; !ok {
+			changes[field] = models.AuditChange{Before: value}
+		}
+	}
+	return changes, nil
+}
+
+// auditFields flattens a record into its JSON fields, leaving out ignored ones
+func auditFields(record interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if record == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for field := range fields {
+		if auditIgnored[field] {
+			delete(fields, field)
+		}
+	}
+	return fields, nil
+}
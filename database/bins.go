@@ -0,0 +1,232 @@
+// database/bins.go
+package database
+
+import (
+	"errors"
+	"fmt"
+	"inventory_system/models"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock is returned when a bin or location holds less stock than requested
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// GetReceivingBin returns the receiving bin of a location, creating it if needed
+func GetReceivingBin(tx *gorm.DB, location string) (models.Bin, error) {
+	bin := models.Bin{Location: location, Code: models.ReceivingBin}
+	err := tx.Where("location = ? AND code = ?", location, models.ReceivingBin).FirstOrCreate(&bin).Error
+	return bin, err
+}
+
+// AddToBin increases the stock of a product in a bin. The increase is applied
+// by the database so that concurrent additions are not lost.
+func AddToBin(tx *gorm.DB, productID uint, bin models.Bin, quantity int) error {
+	stock := models.BinStock{ProductID: productID, BinID: bin.ID, Quantity: quantity}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "bin_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"quantity": gorm.Expr("bin_stocks.quantity + ?", quantity)}),
+	}).Create(&stock).Error
+}
+
+// RemoveFromBin decreases the stock of a product in a bin. The check and the
+// decrease are one statement, so concurrent removals cannot overdraw the bin.
+func RemoveFromBin(tx *gorm.DB, productID uint, bin models.Bin, quantity int) error {
+	result := tx.Model(&models.BinStock{}).
+		Where("product_id = ? AND bin_id = ? AND quantity >= ?", productID, bin.ID, quantity).
+		Update("quantity", gorm.Expr("quantity - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+// PickFromLocation removes stock of a product from the bins of a location.
+// Storage bins are emptied in code order before the receiving bin is touched.
+func PickFromLocation(tx *gorm.DB, productID uint, location string, quantity int) error {
+	var stocks []models.BinStock
+	// Lock the stock so that concurrent picks wait and see what this one leaves
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("bin_stocks.*").
+		Joins("JOIN bins ON bin_stocks.bin_id = bins.id").
+		Where("bin_stocks.product_id = ? AND bins.location = ? AND bin_stocks.quantity > 0", productID, location).
+		Order("CASE WHEN bins.code = '" + models.ReceivingBin + "' THEN 1 ELSE 0 END, bins.code").
+		Find(&stocks).Error
+	if err != nil {
+		return err
+	}
+
+	available := 0
+	for _, stock := range stocks {
+		available += stock.Quantity
+	}
+	if available < quantity {
+		return ErrInsufficientStock
+	}
+
+	remaining := quantity
+	for _, stock := range stocks {
+		if remaining == 0 {
+			break
+		}
+		take := stock.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		if err := tx.Model(&models.BinStock{}).
+			Where("product_id = ? AND bin_id = ?", stock.ProductID, stock.BinID).
+			Update("quantity", stock.Quantity-take).Error; err != nil {
+			return err
+		}
+		remaining -= take
+	}
+	return nil
+}
+
+// SyncInventory recalculates the location-level inventory of a product by
+// summing its bins, audits the change and records it in the outbox
+func SyncInventory(tx *gorm.DB, productID uint, location string) (models.Inventory, error) {
+	var before *models.Inventory
+	var existing []models.Inventory
+	if err := tx.Where("product_id = ? AND location = ?", productID, location).Limit(1).Find(&existing).Error; err != nil {
+		return models.Inventory{}, err
+	}
+	if len(existing) > 0 {
+		before = &existing[0]
+	}
+
+	var total int64
+	err := tx.Table("bin_stocks").
+		Select("COALESCE(SUM(bin_stocks.quantity), 0)").
+		Joins("JOIN bins ON bin_stocks.bin_id = bins.id").
+		Where("bin_stocks.product_id = ? AND bins.location = ?", productID, location).
+		Scan(&total).Error
+	if err != nil {
+		return models.Inventory{}, err
+	}
+
+	inventory := models.Inventory{ProductID: productID, Location: location, Quantity: int(total)}
+	if err := tx.Save(&inventory).Error; err != nil {
+		return inventory, err
+	}
+
+	entityID := fmt.Sprintf("%d:%s", productID, location)
+	previous := 0
+	if before == nil {
+		err = RecordAudit(tx, models.EntityInventory, entityID, nil, inventory)
+	} else {
+		previous = before.Quantity
+		err = RecordAudit(tx, models.EntityInventory, entityID, *before, inventory)
+	}
+	if err != nil {
+		return inventory, err
+	}
+	return inventory, recordInventoryChange(tx, entityID, previous, inventory)
+}
+
+// BackfillBins moves location-level stock that has no bin records into the receiving bin
+func BackfillBins(db *gorm.DB) error {
+	var inventories []models.Inventory
+	err := db.Where("quantity > 0 AND NOT EXISTS (?)",
+		db.Table("bin_stocks").
+			Select("1").
+			Joins("JOIN bins ON bin_stocks.bin_id = bins.id").
+			Where("bin_stocks.product_id = inventories.product_id AND bins.location = inventories.location"),
+	).Find(&inventories).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, inventory := range inventories {
+			bin, err := GetReceivingBin(tx, inventory.Location)
+			if err != nil {
+				return err
+			}
+			if err := AddToBin(tx, inventory.ProductID, bin, inventory.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// StockDrift is a location-level stock figure that disagrees with the stock
+// held in the location's bins
+type StockDrift struct {
+	ProductID uint   `json:"product_id"`
+	Location  string `json:"location"`
+	Recorded  int    `json:"recorded"`
+	Actual    int    `json:"actual"`
+}
+
+// RecomputeInventory compares every location-level inventory row with the sum
+// of its bins and, when apply is set, rewrites the rows that drifted
+func RecomputeInventory(db *gorm.DB, apply bool) ([]StockDrift, error) {
+	type stockKey struct {
+		ProductID uint
+		Location  string
+	}
+	var binTotals []struct {
+		ProductID uint
+		Location  string
+		Quantity  int
+	}
+	err := db.Table("bin_stocks").
+		Select("bin_stocks.product_id, bins.location, SUM(bin_stocks.quantity) AS quantity").
+		Joins("JOIN bins ON bin_stocks.bin_id = bins.id").
+		Group("bin_stocks.product_id, bins.location").
+		Scan(&binTotals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var inventories []models.Inventory
+	if err := db.Find(&inventories).Error; err != nil {
+		return nil, err
+	}
+
+	actual := make(map[stockKey]int, len(binTotals))
+	for _, total := range binTotals {
+		actual[stockKey{total.ProductID, total.Location}] = total.Quantity
+	}
+
+	drifts := []StockDrift{}
+	for _, inventory := range inventories {
+		key := stockKey{inventory.ProductID, inventory.Location}
+		if quantity := actual[key]; quantity != inventory.Quantity {
+			drifts = append(drifts, StockDrift{key.ProductID, key.Location, inventory.Quantity, quantity})
+		}
+		delete(actual, key)
+	}
+	// Bins holding stock with no inventory row at all
+	for key, quantity := range actual {
+		if quantity != 0 {
+			drifts = append(drifts, StockDrift{key.ProductID, key.Location, 0, quantity})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].ProductID != drifts[j].ProductID {
+			return drifts[i].ProductID < drifts[j].ProductID
+		}
+		return drifts[i].Location < drifts[j].Location
+	})
+
+	if !apply || len(drifts) == 0 {
+		return drifts, nil
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, drift := range drifts {
+			if _, err := SyncInventory(tx, drift.ProductID, drift.Location); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return drifts, err
+}
@@ -0,0 +1,53 @@
+// database/bins_test.go
+package database
+
+import (
+	"errors"
+	"inventory_system/models"
+	"testing"
+)
+
+func TestBinStockAddsAndRemoves(t *testing.T) {
+	db := openTestDB(t)
+
+	product := models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	bin := models.Bin{Location: "Store 1", Code: "A-01"}
+	if err := db.Create(&bin).Error; err != nil {
+		t.Fatalf("creating bin: %v", err)
+	}
+
+	stockInBin := func() int {
+		t.Helper()
+		var stock models.BinStock
+		if err := db.Where("product_id = ? AND bin_id = ?", product.ID, bin.ID).First(&stock).Error; err != nil {
+			t.Fatalf("loading bin stock: %v", err)
+		}
+		return stock.Quantity
+	}
+
+	if err := RemoveFromBin(db, product.ID, bin, 1); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("removing from an empty bin returned %v, want ErrInsufficientStock", err)
+	}
+
+	for _, quantity := range []int{5, 3} {
+		if err := AddToBin(db, product.ID, bin, quantity); err != nil {
+			t.Fatalf("adding %d: %v", quantity, err)
+		}
+	}
+	if quantity := stockInBin(); quantity != 8 {
+		t.Errorf("bin holds %d after adding 5 and 3, want 8", quantity)
+	}
+
+	if err := RemoveFromBin(db, product.ID, bin, 9); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("removing more than the bin holds returned %v, want ErrInsufficientStock", err)
+	}
+	if err := RemoveFromBin(db, product.ID, bin, 8); err != nil {
+		t.Fatalf("removing the whole stock: %v", err)
+	}
+	if quantity := stockInBin(); quantity != 0 {
+		t.Errorf("bin holds %d after removing everything, want 0", quantity)
+	}
+}
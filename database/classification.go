@@ -0,0 +1,272 @@
+// database/classification.go
+package database
+
+import (
+	"inventory_system/models"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ABC classes split products by cumulative share of revenue
+const (
+	abcClassA = 0.80
+	abcClassB = 0.95
+)
+
+// XYZ classes split products by the coefficient of variation of weekly demand
+const (
+	xyzClassX = 0.5
+	xyzClassY = 1.0
+)
+
+// CountFrequency is how many days may pass between counts of a product in each ABC class.
+// Unclassified products are counted as C items.
+var CountFrequency = map[string]int{"A": 30, "B": 90, "C": 180}
+
+// ProductClass holds the classification of one product and the figures behind it
+type ProductClass struct {
+	ProductID       uint    `json:"product_id"`
+	Name            string  `json:"name"`
+	Revenue         float64 `json:"revenue"`
+	RevenueShare    float64 `json:"revenue_share"`
+	CumulativeShare float64 `json:"cumulative_share"`
+	AbcClass        string  `json:"abc_class"`
+	MeanDemand      float64 `json:"mean_weekly_demand"`
+	Variation       float64 `json:"variation"`
+	XyzClass        string  `json:"xyz_class"`
+}
+
+// Classification is the outcome of one classification run
+type Classification struct {
+	WindowDays   int            `json:"window_days"`
+	ClassifiedAt time.Time      `json:"classified_at"`
+	Counts       map[string]int `json:"counts"`
+	Products     []ProductClass `json:"products"`
+}
+
+// ClassifyProducts classifies every product by its revenue contribution (ABC) and
+// the variability of its weekly demand (XYZ) over the last windowDays, with weeks
+// starting Monday in loc, and stores the classes on the products
+func ClassifyProducts(db *gorm.DB, windowDays int, now time.Time, loc *time.Location) (Classification, error) {
+	result := Classification{WindowDays: windowDays, ClassifiedAt: now, Counts: map[string]int{}}
+	since := now.AddDate(0, 0, -windowDays)
+
+	var products []models.Product
+	if err := db.Select("id, name, created_at").Order("id").Find(&products).Error; err != nil {
+		return result, err
+	}
+
+	// Whole weeks inside the window, used for demand variability. The weeks the
+	// window starts and ends in are partial and would understate their demand.
+	var weekStarts []time.Time
+	weeks := make(map[int64]int)
+	start := BucketStart(since, GranularityWeek, loc)
+	if start.Before(since) {
+		start = NextBucket(start, GranularityWeek)
+	}
+	for ; !NextBucket(start, GranularityWeek).After(now); start = NextBucket(start, GranularityWeek) {
+		weeks[start.Unix()] = len(weekStarts)
+		weekStarts = append(weekStarts, start)
+	}
+
+	revenue := make(map[uint]float64)
+	demand := make(map[uint][]float64)
+	firstSale := make(map[uint]time.Time)
+	rows, err := db.Table("orders").
+		Select("product_id, order_date, quantity, total_price").
+		Where("order_date >= ? AND order_date < ?", since, now).
+		Rows()
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID uint
+		var orderDate time.Time
+		var quantity int
+		var totalPrice float64
+		if err := rows.Scan(&productID, &orderDate, &quantity, &totalPrice); err != nil {
+			return result, err
+		}
+
+		revenue[productID] += totalPrice
+		if first, ok := firstSale[productID]; !ok || orderDate.Before(first) {
+			firstSale[productID] = orderDate
+		}
+		if _, ok := demand[productID]; !ok {
+			demand[productID] = make([]float64, len(weekStarts))
+		}
+		if week, ok := weeks[BucketStart(orderDate, GranularityWeek, loc).Unix()]; ok {
+			demand[productID][week] += float64(quantity)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	total := 0.0
+	for _, value := range revenue {
+		total += value
+	}
+
+	for _, product := range products {
+		class := ProductClass{ProductID: product.ID, Name: product.Name, Revenue: revenue[product.ID]}
+		if total > 0 {
+			class.RevenueShare = class.Revenue / total
+		}
+		class.MeanDemand, class.Variation = variation(activeWeeks(demand[product.ID], weekStarts, product, firstSale))
+		class.XyzClass = xyzClass(class.MeanDemand, class.Variation)
+		result.Products = append(result.Products, class)
+	}
+
+	// Highest revenue first; a product is A while the share before it is under the A cut-off
+	sort.SliceStable(result.Products, func(i, j int) bool {
+		return result.Products[i].Revenue > result.Products[j].Revenue
+	})
+	cumulative := 0.0
+	for i := range result.Products {
+		class := &result.Products[i]
+		switch {
+		case class.Revenue == 0:
+			class.AbcClass = "C"
+		case cumulative < abcClassA:
+			class.AbcClass = "A"
+		case cumulative < abcClassB:
+			class.AbcClass = "B"
+		default:
+			class.AbcClass = "C"
+		}
+		cumulative += class.RevenueShare
+		class.CumulativeShare = cumulative
+		result.Counts[class.AbcClass+class.XyzClass]++
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var previous []models.Product
+		if err := tx.Select("id, abc_class, xyz_class").Find(&previous).Error; err != nil {
+			return err
+		}
+		classes := make(map[uint]map[string]string, len(previous))
+		for _, product := range previous {
+			classes[product.ID] = map[string]string{"abc_class": product.AbcClass, "xyz_class": product.XyzClass}
+		}
+
+		for _, class := range result.Products {
+			if err := tx.Model(&models.Product{}).Where("id = ?", class.ProductID).UpdateColumns(map[string]interface{}{
+				"abc_class":     class.AbcClass,
+				"xyz_class":     class.XyzClass,
+				"classified_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			// Only a change of class is audited, not the time it was checked
+			after := map[string]string{"abc_class": class.AbcClass, "xyz_class": class.XyzClass}
+			if err := RecordAudit(tx, models.EntityProduct, strconv.FormatUint(uint64(class.ProductID), 10), classes[class.ProductID], after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// activeWeeks returns the weekly demand of a product from the first whole week
+// it was on sale, so that weeks before it was created or first sold do not
+// count as weeks without demand
+func activeWeeks(weekly []float64, weekStarts []time.Time, product models.Product, firstSale map[uint]time.Time) []float64 {
+	if len(weekly) == 0 {
+		return nil
+	}
+
+	onSale := product.CreatedAt
+	if first, ok := firstSale[product.ID]; ok && first.Before(onSale) {
+		onSale = first
+	}
+	for i, start := range weekStarts {
+		if !start.Before(onSale) {
+			return weekly[i:]
+		}
+	}
+	return nil
+}
+
+// variation returns the mean and coefficient of variation of weekly demand
+func variation(weekly []float64) (float64, float64) {
+	if len(weekly) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, value := range weekly {
+		sum += value
+	}
+	mean := sum / float64(len(weekly))
+	if mean == 0 {
+		return 0, 0
+	}
+
+	squares := 0.0
+	for _, value := range weekly {
+		squares += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(squares/float64(len(weekly))) / mean
+}
+
+// xyzClass maps demand variability to a class; products without demand are Z
+func xyzClass(mean, variation float64) string {
+	switch {
+	case mean == 0:
+		return "Z"
+	case variation <= xyzClassX:
+		return "X"
+	case variation <= xyzClassY:
+		return "Y"
+	default:
+		return "Z"
+	}
+}
+
+// GetCountsDue returns the inventory of a location whose last approved count is
+// older than the count frequency of the product's ABC class
+func GetCountsDue(db *gorm.DB, location string, now time.Time) ([]models.Inventory, error) {
+	var inventories []models.Inventory
+	if err := db.Preload("Product").Where("location = ?", location).Order("product_id").Find(&inventories).Error; err != nil {
+		return nil, err
+	}
+
+	var counted []struct {
+		ProductID     uint
+		LastCountedAt aggregateTime
+	}
+	if err := db.Table("count_lines").
+		Select("count_lines.product_id, MAX(count_sessions.closed_at) as last_counted_at").
+		Joins("JOIN count_sessions ON count_lines.session_id = count_sessions.id").
+		Where("count_sessions.status = ? AND count_lines.location = ?", models.CountApproved, location).
+		Group("count_lines.product_id").
+		Scan(&counted).Error; err != nil {
+		return nil, err
+	}
+	lastCounted := make(map[uint]time.Time, len(counted))
+	for _, row := range counted {
+		lastCounted[row.ProductID] = row.LastCountedAt.Time
+	}
+
+	due := []models.Inventory{}
+	for _, inventory := range inventories {
+		frequency, ok := CountFrequency[inventory.Product.AbcClass]
+		if !ok {
+			frequency = CountFrequency["C"]
+		}
+		last, ok := lastCounted[inventory.ProductID]
+		if !ok || !last.AddDate(0, 0, frequency).After(now) {
+			due = append(due, inventory)
+		}
+	}
+	return due, nil
+}
@@ -0,0 +1,105 @@
+// database/classification_test.go
+package database
+
+import (
+	"inventory_system/models"
+	"testing"
+	"time"
+)
+
+func TestClassifyProductsSteadyDemandIsX(t *testing.T) {
+	db := openTestDB(t)
+
+	// A Wednesday, so the current week has already seen its Tuesday sale
+	now := time.Date(2026, 6, 17, 12, 0, 0, 0, time.Local)
+	created := now.AddDate(0, 0, -8*7)
+
+	steady := models.Product{Name: "Steady", Category: "Electronics", Price: 10, CreatedAt: created}
+	idle := models.Product{Name: "Idle", Category: "Electronics", Price: 10, CreatedAt: created}
+	if err := db.Create(&[]*models.Product{&steady, &idle}).Error; err != nil {
+		t.Fatalf("creating products: %v", err)
+	}
+
+	// Ten units every Tuesday since the product was created, including this week
+	for sale := created.AddDate(0, 0, -1); sale.Before(now); sale = sale.AddDate(0, 0, 7) {
+		if sale.Before(created) {
+			continue
+		}
+		order := models.Order{ProductID: steady.ID, Quantity: 10, Location: "Warehouse A", OrderDate: sale, TotalPrice: 100}
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatalf("creating order: %v", err)
+		}
+	}
+
+	result, err := ClassifyProducts(db, 365, now, time.Local)
+	if err != nil {
+		t.Fatalf("classifying: %v", err)
+	}
+
+	classes := make(map[uint]ProductClass)
+	for _, class := range result.Products {
+		classes[class.ProductID] = class
+	}
+
+	got := classes[steady.ID]
+	if got.XyzClass != "X" {
+		t.Errorf("steady product is %s with variation %.2f, want X", got.XyzClass, got.Variation)
+	}
+	if got.MeanDemand != 10 {
+		t.Errorf("steady product has mean weekly demand %.2f, want 10", got.MeanDemand)
+	}
+	if got.AbcClass != "A" {
+		t.Errorf("steady product is %s, want A", got.AbcClass)
+	}
+	if class := classes[idle.ID]; class.XyzClass != "Z" || class.AbcClass != "C" {
+		t.Errorf("product without sales is %s%s, want CZ", class.AbcClass, class.XyzClass)
+	}
+
+	var stored models.Product
+	if err := db.First(&stored, steady.ID).Error; err != nil {
+		t.Fatalf("reading product: %v", err)
+	}
+	if stored.AbcClass != "A" || stored.XyzClass != "X" {
+		t.Errorf("stored class is %s%s, want AX", stored.AbcClass, stored.XyzClass)
+	}
+}
+
+func TestClassifyProductsBucketsWeeksInZone(t *testing.T) {
+	db := openTestDB(t)
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	created := time.Date(2026, 4, 20, 0, 0, 0, 0, loc)
+	now := time.Date(2026, 6, 17, 12, 0, 0, 0, loc)
+
+	product := models.Product{Name: "Weekender", Category: "Electronics", Price: 10, CreatedAt: created}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+
+	// Every other Sunday noon and the Monday just after midnight that follows.
+	// Both fall on the same Sunday in UTC, but in different weeks in loc.
+	for sunday := created.AddDate(0, 0, 6); sunday.Before(now); sunday = sunday.AddDate(0, 0, 14) {
+		for _, sale := range []time.Time{sunday.Add(12 * time.Hour), sunday.Add(24*time.Hour + 30*time.Minute)} {
+			order := models.Order{ProductID: product.ID, Quantity: 10, Location: "Warehouse A", OrderDate: sale, TotalPrice: 100}
+			if err := db.Create(&order).Error; err != nil {
+				t.Fatalf("creating order: %v", err)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		loc  *time.Location
+		want string
+	}{
+		{loc, "X"},
+		{time.UTC, "Y"},
+	} {
+		result, err := ClassifyProducts(db, 365, now, tc.loc)
+		if err != nil {
+			t.Fatalf("classifying: %v", err)
+		}
+		if got := result.Products[0]; got.XyzClass != tc.want {
+			t.Errorf("in %s the product is %s with variation %.2f, want %s", tc.loc, got.XyzClass, got.Variation, tc.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// database/costing.go
+package database
+
+import (
+	"fmt"
+	"inventory_system/models"
+	"math"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Supported costing methods
+const (
+	CostingFIFO     = "fifo"
+	CostingAverage  = "average"
+	CostingStandard = "standard"
+)
+
+// CostingMethod is the costing method used to value stock and cost of goods sold.
+// FIFO layers and the moving average are always maintained, so the method only
+// decides which of them values issues.
+var CostingMethod = CostingAverage
+
+// SetCostingMethod selects the costing method for this deployment
+func SetCostingMethod(method string) error {
+	switch method {
+	case CostingFIFO, CostingAverage, CostingStandard:
+		CostingMethod = method
+		return nil
+	}
+	return fmt.Errorf("unknown costing method %q", method)
+}
+
+// CurrentUnitCost returns the unit cost at which new stock of a product is valued
+// when no explicit cost is given
+func CurrentUnitCost(product models.Product) float64 {
+	if CostingMethod == CostingStandard || product.AverageCost == 0 {
+		return product.StandardCost
+	}
+	return product.AverageCost
+}
+
+// applyMovementCost values a movement under the costing method, updating the
+// FIFO layers and moving average of the product. Bin-to-bin movements do not
+// change the value of stock and are left at zero cost.
+func applyMovementCost(tx *gorm.DB, movement *models.StockMovement) error {
+	if movement.Type == models.MovementPutaway || movement.Type == models.MovementTransfer || movement.Quantity == 0 {
+		return nil
+	}
+
+	var product models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, movement.ProductID).Error; err != nil {
+		return err
+	}
+
+	if movement.Quantity > 0 {
+		return receiveCost(tx, &product, movement)
+	}
+	return issueCost(tx, &product, movement)
+}
+
+// receiveCost adds a cost layer for incoming stock and moves the weighted average
+func receiveCost(tx *gorm.DB, product *models.Product, movement *models.StockMovement) error {
+	if movement.UnitCost == 0 {
+		movement.UnitCost = CurrentUnitCost(*product)
+	}
+
+	onHand, err := layerQuantity(tx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Create(&models.CostLayer{
+		ProductID:  product.ID,
+		MovementID: movement.ID,
+		Quantity:   movement.Quantity,
+		Remaining:  movement.Quantity,
+		UnitCost:   movement.UnitCost,
+		ReceivedAt: movement.CreatedAt,
+	}).Error; err != nil {
+		return err
+	}
+
+	average := (float64(onHand)*product.AverageCost + float64(movement.Quantity)*movement.UnitCost) /
+		float64(onHand+movement.Quantity)
+	// The update writes the new average back into product, so the old one is
+	// kept first. Both are audited at the column's precision to leave out
+	// floating-point noise.
+	before := map[string]float64{"average_cost": math.Round(product.AverageCost*1e4) / 1e4}
+	if err := tx.Model(product).UpdateColumn("average_cost", average).Error; err != nil {
+		return err
+	}
+	after := map[string]float64{"average_cost": math.Round(average*1e4) / 1e4}
+	if err := RecordAudit(tx, models.EntityProduct, strconv.FormatUint(uint64(product.ID), 10), before, after); err != nil {
+		return err
+	}
+
+	// Standard costing always values receipts at standard cost
+	if CostingMethod == CostingStandard {
+		movement.CostValue = float64(movement.Quantity) * product.StandardCost
+	} else {
+		movement.CostValue = float64(movement.Quantity) * movement.UnitCost
+	}
+	return nil
+}
+
+// issueCost consumes FIFO layers for outgoing stock and values the issue
+func issueCost(tx *gorm.DB, product *models.Product, movement *models.StockMovement) error {
+	quantity := -movement.Quantity
+
+	fifoValue, err := fifoCost(tx, *product, quantity, true)
+	if err != nil {
+		return err
+	}
+
+	var value float64
+	switch CostingMethod {
+	case CostingFIFO:
+		value = fifoValue
+	case CostingStandard:
+		value = float64(quantity) * product.StandardCost
+	default:
+		value = float64(quantity) * product.AverageCost
+	}
+
+	movement.UnitCost = value / float64(quantity)
+	movement.CostValue = -value
+	return nil
+}
+
+// fifoCost values an issue of a product from its oldest cost layers, consuming
+// them when consume is set
+func fifoCost(tx *gorm.DB, product models.Product, quantity int, consume bool) (float64, error) {
+	var layers []models.CostLayer
+	if err := tx.Where("product_id = ? AND remaining > 0", product.ID).
+		Order("received_at, id").
+		Find(&layers).Error; err != nil {
+		return 0, err
+	}
+
+	value := 0.0
+	remaining := quantity
+	for _, layer := range layers {
+		if remaining == 0 {
+			break
+		}
+		take := layer.Remaining
+		if take > remaining {
+			take = remaining
+		}
+		if consume {
+			if err := tx.Model(&models.CostLayer{}).Where("id = ?", layer.ID).
+				Update("remaining", layer.Remaining-take).Error; err != nil {
+				return 0, err
+			}
+		}
+		value += float64(take) * layer.UnitCost
+		remaining -= take
+	}
+	// Stock that predates cost tracking is valued at the average cost
+	value += float64(remaining) * product.AverageCost
+	return value, nil
+}
+
+// VarianceValue returns the value a count variance of a product would be posted
+// at: gains at the current unit cost and losses at the cost of the stock they
+// issue under the costing method
+func VarianceValue(db *gorm.DB, product models.Product, variance int) (float64, error) {
+	if variance >= 0 {
+		return float64(variance) * CurrentUnitCost(product), nil
+	}
+
+	switch CostingMethod {
+	case CostingFIFO:
+		value, err := fifoCost(db, product, -variance, false)
+		return -value, err
+	case CostingStandard:
+		return float64(variance) * product.StandardCost, nil
+	default:
+		return float64(variance) * product.AverageCost, nil
+	}
+}
+
+// layerQuantity returns the quantity of a product still held in cost layers
+func layerQuantity(tx *gorm.DB, productID uint) (int, error) {
+	var total int64
+	err := tx.Model(&models.CostLayer{}).
+		Select("COALESCE(SUM(remaining), 0)").
+		Where("product_id = ?", productID).
+		Scan(&total).Error
+	return int(total), err
+}
+
+// BackfillOpeningBalances records an opening movement for stock that has no
+// movement history, so that it carries a cost and can be reconstructed later
+func BackfillOpeningBalances(db *gorm.DB) error {
+	var inventories []models.Inventory
+	err := db.Where("quantity > 0 AND NOT EXISTS (?)",
+		db.Table("stock_movements").
+			Select("1").
+			Where("stock_movements.product_id = inventories.product_id AND stock_movements.location = inventories.location"),
+	).Find(&inventories).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, inventory := range inventories {
+			movement := models.StockMovement{
+				ProductID: inventory.ProductID,
+				Location:  inventory.Location,
+				Quantity:  inventory.Quantity,
+				Type:      models.MovementOpening,
+			}
+			if err := RecordMovement(tx, &movement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
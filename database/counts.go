@@ -0,0 +1,145 @@
+// database/counts.go
+package database
+
+import (
+	"errors"
+	"fmt"
+	"inventory_system/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrCountOverlap is returned when a product and location is already part of an open count
+var ErrCountOverlap = errors.New("some products are already part of an open count")
+
+// OpenCountSession creates a count session with one line per inventory row,
+// snapshotting the expected quantities. A product and location can only be part
+// of one open count at a time.
+func OpenCountSession(tx *gorm.DB, session *models.CountSession, inventories []models.Inventory) error {
+	var overlapping int64
+	for _, inventory := range inventories {
+		err := tx.Model(&models.CountLine{}).
+			Joins("JOIN count_sessions ON count_lines.session_id = count_sessions.id").
+			Where("count_sessions.status = ? AND count_lines.product_id = ? AND count_lines.location = ?",
+				models.CountOpen, inventory.ProductID, inventory.Location).
+			Count(&overlapping).Error
+		if err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrCountOverlap
+		}
+	}
+
+	if err := tx.Create(session).Error; err != nil {
+		return err
+	}
+
+	for _, inventory := range inventories {
+		session.Lines = append(session.Lines, models.CountLine{
+			SessionID:        session.ID,
+			ProductID:        inventory.ProductID,
+			Location:         inventory.Location,
+			ExpectedQuantity: inventory.Quantity,
+		})
+	}
+	return tx.Create(&session.Lines).Error
+}
+
+// PostCountVariance adjusts the stock of a counted line by the difference between
+// the counted and expected quantity. Movements made while the count was open are
+// kept, so only the discrepancy found by the count is applied.
+func PostCountVariance(tx *gorm.DB, line models.CountLine) error {
+	if line.CountedQuantity == nil {
+		return fmt.Errorf("count line %d has not been counted", line.ID)
+	}
+
+	variance := *line.CountedQuantity - line.ExpectedQuantity
+	if variance == 0 {
+		return nil
+	}
+
+	if variance > 0 {
+		bin, err := GetReceivingBin(tx, line.Location)
+		if err != nil {
+			return err
+		}
+		if err := AddToBin(tx, line.ProductID, bin, variance); err != nil {
+			return err
+		}
+	} else if err := PickFromLocation(tx, line.ProductID, line.Location, -variance); err != nil {
+		return err
+	}
+
+	sessionID := line.SessionID
+	movement := models.StockMovement{
+		ProductID:      line.ProductID,
+		Location:       line.Location,
+		Quantity:       variance,
+		Type:           models.MovementCount,
+		Reason:         models.ReasonCount,
+		Reference:      fmt.Sprintf("count:%d", line.SessionID),
+		CountSessionID: &sessionID,
+	}
+	if err := RecordMovement(tx, &movement); err != nil {
+		return err
+	}
+
+	_, err := SyncInventory(tx, line.ProductID, line.Location)
+	return err
+}
+
+// CountFlaggedMovements returns the number of movements recorded per product and
+// location while a count session was open
+func CountFlaggedMovements(db *gorm.DB, sessionID uint) (map[string]int, error) {
+	var rows []struct {
+		ProductID uint
+		Location  string
+		Total     int
+	}
+
+	err := db.Table("stock_movements").
+		Select("product_id, location, COUNT(*) as total").
+		Where("count_session_id = ? AND type <> ?", sessionID, models.MovementCount).
+		Group("product_id, location").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	flagged := make(map[string]int, len(rows))
+	for _, row := range rows {
+		flagged[CountLineKey(row.ProductID, row.Location)] = row.Total
+	}
+	return flagged, nil
+}
+
+// CountPostedValues returns the cost value per product and location of the
+// variances an approved count session posted
+func CountPostedValues(db *gorm.DB, sessionID uint) (map[string]float64, error) {
+	var rows []struct {
+		ProductID uint
+		Location  string
+		Total     float64
+	}
+
+	err := db.Table("stock_movements").
+		Select("product_id, location, SUM(cost_value) as total").
+		Where("count_session_id = ? AND type = ?", sessionID, models.MovementCount).
+		Group("product_id, location").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	posted := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		posted[CountLineKey(row.ProductID, row.Location)] = row.Total
+	}
+	return posted, nil
+}
+
+// CountLineKey identifies a product at a location
+func CountLineKey(productID uint, location string) string {
+	return fmt.Sprintf("%d@%s", productID, location)
+}
@@ -0,0 +1,37 @@
+// database/db_test.go
+package database
+
+import (
+	"context"
+	"inventory_system/config"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// openTestDB connects to a migrated SQLite database in a temporary directory
+// and returns it scoped to the default tenant
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.Path = filepath.Join(t.TempDir(), "test.db")
+	cfg.Log.Level = "silent"
+
+	db, err := Connect(&cfg)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return db.WithContext(WithTenant(context.Background(), DefaultTenantID))
+}
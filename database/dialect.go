@@ -0,0 +1,113 @@
+// database/dialect.go
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"inventory_system/config"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Open returns the GORM dialector for the configured database driver
+func Open(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case config.DriverMySQL:
+		return mysql.Open(cfg.DSN()), nil
+	case config.DriverPostgres:
+		return postgres.Open(cfg.DSN()), nil
+	case config.DriverSQLite:
+		return sqlite.Open(cfg.DSN()), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// The SQL below differs between databases; every query that is not portable
+// takes its dialect-specific parts from here
+
+// monthExpression formats a date column as YYYY-MM
+func monthExpression(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM')", column)
+	case "sqlite":
+		return fmt.Sprintf("strftime('%%Y-%%m', %s)", column)
+	default:
+		return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m')", column)
+	}
+}
+
+// timestampType returns the column type the migrations use for timestamps
+func timestampType(db *gorm.DB) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "TIMESTAMPTZ"
+	case "sqlite":
+		return "DATETIME"
+	default:
+		return "DATETIME(3)"
+	}
+}
+
+// primaryKeyHint returns the index hint forcing a join to use the primary key,
+// which only MySQL supports; the other planners choose it on their own
+func primaryKeyHint(db *gorm.DB) string {
+	if db.Dialector.Name() == "mysql" {
+		return " USE INDEX (PRIMARY)"
+	}
+	return ""
+}
+
+// sqliteTimeLayouts are the formats SQLite returns times in when it cannot tell
+// a column holds a time, as with the result of MAX
+var sqliteTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// aggregateTime scans a time computed by an aggregate such as MAX, which SQLite
+// returns as text rather than a time
+type aggregateTime struct {
+	time.Time
+}
+
+// Scan implements sql.Scanner
+func (t *aggregateTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a time", value)
+	}
+}
+
+// Value implements driver.Valuer
+func (t aggregateTime) Value() (driver.Value, error) {
+	return t.Time, nil
+}
+
+func (t *aggregateTime) parse(value string) error {
+	for _, layout := range sqliteTimeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as a time", value)
+}
@@ -0,0 +1,427 @@
+// database/forecast.go
+package database
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supported forecasting methods
+const (
+	ForecastAuto              = "auto"
+	ForecastMovingAverage     = "moving_average"
+	ForecastExponentialSmooth = "exponential_smoothing"
+	ForecastHoltWinters       = "holt_winters"
+)
+
+// Limits on the history a forecast is fitted to, how far ahead it looks and
+// how many rolling origins a backtest fits every method at
+const (
+	MaxForecastHistoryDays = 3 * 365
+	MaxForecastHorizonDays = 365
+	MaxBacktestFolds       = 12
+)
+
+// Model settings: forecasts are daily with weekly seasonality, and smoothing
+// factors are chosen by grid search
+const (
+	movingAverageWindow      = 7
+	forecastSeasonLength     = 7
+	forecastGridStep         = 0.05
+	forecastSeasonalGridStep = 0.1
+)
+
+// ForecastMethods lists the fitted forecasting methods in order of complexity
+var ForecastMethods = []string{ForecastMovingAverage, ForecastExponentialSmooth, ForecastHoltWinters}
+
+// ErrInsufficientHistory is returned when there are too few days of history to fit a model
+var ErrInsufficientHistory = errors.New("not enough order history to fit the forecasting model")
+
+// confidenceZ maps the supported confidence levels to normal quantiles
+var confidenceZ = map[float64]float64{0.80: 1.2816, 0.90: 1.6449, 0.95: 1.9600, 0.99: 2.5758}
+
+// ForecastPoint is the forecast demand for one day with its confidence interval
+type ForecastPoint struct {
+	Date     string  `json:"date"`
+	Forecast float64 `json:"forecast"`
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+}
+
+// DemandForecast is the daily demand forecast of one product
+type DemandForecast struct {
+	ProductID   uint               `json:"product_id"`
+	Method      string             `json:"method"`
+	Parameters  map[string]float64 `json:"parameters"`
+	HistoryDays int                `json:"history_days"`
+	HorizonDays int                `json:"horizon_days"`
+	Confidence  float64            `json:"confidence"`
+	StdDev      float64            `json:"residual_std_dev"`
+	Total       float64            `json:"total_forecast"`
+	Points      []ForecastPoint    `json:"points"`
+}
+
+// ForecastError summarises the out-of-sample error of one method in a backtest
+type ForecastError struct {
+	Method string   `json:"method"`
+	Folds  int      `json:"folds"`
+	MAE    float64  `json:"mae"`
+	RMSE   float64  `json:"rmse"`
+	MAPE   *float64 `json:"mape"`
+	Bias   float64  `json:"bias"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// ForecastBacktest compares the methods on the most recent history held out
+type ForecastBacktest struct {
+	ProductID   uint            `json:"product_id"`
+	HistoryDays int             `json:"history_days"`
+	HorizonDays int             `json:"horizon_days"`
+	Folds       int             `json:"folds"`
+	Best        string          `json:"best,omitempty"`
+	Methods     []ForecastError `json:"methods"`
+}
+
+// forecastFit is a fitted model ready to forecast
+type forecastFit struct {
+	params   map[string]float64
+	stdDev   float64
+	forecast []float64
+}
+
+// IsValidForecastMethod reports whether the forecasting method is supported
+func IsValidForecastMethod(method string) bool {
+	if method == ForecastAuto {
+		return true
+	}
+	for _, known := range ForecastMethods {
+		if method == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidConfidence reports whether a confidence level is supported
+func IsValidConfidence(confidence float64) bool {
+	_, ok := confidenceZ[confidence]
+	return ok
+}
+
+// GetDailyDemand returns the units of a product ordered on each of the last days
+// whole days before now in loc, oldest first, along with the start of the first day
+func GetDailyDemand(db *gorm.DB, productID uint, days int, now time.Time, loc *time.Location) ([]float64, time.Time, error) {
+	end := BucketStart(now, GranularityDay, loc)
+	start := end.AddDate(0, 0, -days)
+
+	index := make(map[int64]int)
+	for day := start; day.Before(end); day = NextBucket(day, GranularityDay) {
+		index[day.Unix()] = len(index)
+	}
+	demand := make([]float64, len(index))
+
+	rows, err := db.Table("orders").
+		Select("order_date, quantity").
+		Where("product_id = ? AND order_date >= ? AND order_date < ?", productID, start, end).
+		Rows()
+	if err != nil {
+		return nil, start, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderDate time.Time
+		var quantity int
+		if err := rows.Scan(&orderDate, &quantity); err != nil {
+			return nil, start, err
+		}
+		if i, ok := index[BucketStart(orderDate, GranularityDay, loc).Unix()]; ok {
+			demand[i] += float64(quantity)
+		}
+	}
+	return demand, start, rows.Err()
+}
+
+// ForecastDemand fits a model to the daily demand history and forecasts the
+// following horizon days. Intervals assume independent one-step errors and widen
+// with the square root of the horizon; demand is never forecast below zero.
+// The auto method picks the method with the lowest backtest error.
+func ForecastDemand(history []float64, method string, horizon int, confidence float64, start time.Time) (DemandForecast, error) {
+	result := DemandForecast{
+		Method:      method,
+		HistoryDays: len(history),
+		HorizonDays: horizon,
+		Confidence:  confidence,
+		Points:      []ForecastPoint{},
+	}
+
+	z, ok := confidenceZ[confidence]
+	if !ok {
+		return result, fmt.Errorf("unsupported confidence level %v", confidence)
+	}
+
+	if method == ForecastAuto {
+		result.Method = ForecastExponentialSmooth
+		if backtest := BacktestDemand(history, horizon, 1); backtest.Best != "" {
+			result.Method = backtest.Best
+		}
+	}
+
+	fit, err := fitForecast(result.Method, history, horizon)
+	if err != nil {
+		return result, err
+	}
+	result.Parameters = fit.params
+	result.StdDev = fit.stdDev
+
+	day := start.AddDate(0, 0, len(history))
+	for h, value := range fit.forecast {
+		value = math.Max(value, 0)
+		width := z * fit.stdDev * math.Sqrt(float64(h+1))
+		result.Points = append(result.Points, ForecastPoint{
+			Date:     day.Format("2006-01-02"),
+			Forecast: value,
+			Lower:    math.Max(value-width, 0),
+			Upper:    value + width,
+		})
+		result.Total += value
+		day = NextBucket(day, GranularityDay)
+	}
+	return result, nil
+}
+
+// BacktestDemand holds out the last horizon days of history in each of folds
+// rolling origins, forecasts them with every method and reports the errors
+func BacktestDemand(history []float64, horizon, folds int) ForecastBacktest {
+	result := ForecastBacktest{HistoryDays: len(history), HorizonDays: horizon, Folds: folds}
+
+	bestMAE := math.Inf(1)
+	for _, method := range ForecastMethods {
+		summary := ForecastError{Method: method}
+		var absolute, squared, bias, percent float64
+		var count, percentCount int
+
+		for fold := 1; fold <= folds; fold++ {
+			cutoff := len(history) - fold*horizon
+			if cutoff <= 0 {
+				break
+			}
+			fit, err := fitForecast(method, history[:cutoff], horizon)
+			if err != nil {
+				continue
+			}
+
+			summary.Folds++
+			for h, predicted := range fit.forecast {
+				actual := history[cutoff+h]
+				diff := math.Max(predicted, 0) - actual
+				absolute += math.Abs(diff)
+				squared += diff * diff
+				bias += diff
+				count++
+				if actual != 0 {
+					percent += math.Abs(diff) / actual
+					percentCount++
+				}
+			}
+		}
+
+		if count == 0 {
+			summary.Error = ErrInsufficientHistory.Error()
+			result.Methods = append(result.Methods, summary)
+			continue
+		}
+
+		summary.MAE = absolute / float64(count)
+		summary.RMSE = math.Sqrt(squared / float64(count))
+		summary.Bias = bias / float64(count)
+		if percentCount > 0 {
+			mape := 100 * percent / float64(percentCount)
+			summary.MAPE = &mape
+		}
+		if summary.MAE < bestMAE {
+			bestMAE = summary.MAE
+			result.Best = method
+		}
+		result.Methods = append(result.Methods, summary)
+	}
+	return result
+}
+
+// fitForecast fits the given method to history and forecasts horizon days ahead
+func fitForecast(method string, history []float64, horizon int) (forecastFit, error) {
+	switch method {
+	case ForecastMovingAverage:
+		return fitMovingAverage(history, horizon)
+	case ForecastExponentialSmooth:
+		return fitExponentialSmoothing(history, horizon)
+	case ForecastHoltWinters:
+		return fitHoltWinters(history, horizon)
+	default:
+		return forecastFit{}, fmt.Errorf("unknown forecasting method %q", method)
+	}
+}
+
+// fitMovingAverage forecasts the mean of the last week of demand
+func fitMovingAverage(history []float64, horizon int) (forecastFit, error) {
+	window := movingAverageWindow
+	if len(history) < window {
+		window = len(history)
+	}
+	if window == 0 {
+		return forecastFit{}, ErrInsufficientHistory
+	}
+
+	mean := func(values []float64) float64 {
+		sum := 0.0
+		for _, value := range values {
+			sum += value
+		}
+		return sum / float64(len(values))
+	}
+
+	var squared float64
+	var count int
+	for t := window; t < len(history); t++ {
+		diff := history[t] - mean(history[t-window:t])
+		squared += diff * diff
+		count++
+	}
+
+	level := mean(history[len(history)-window:])
+	return forecastFit{
+		params:   map[string]float64{"window": float64(window)},
+		stdDev:   rootMean(squared, count),
+		forecast: constantForecast(level, horizon),
+	}, nil
+}
+
+// fitExponentialSmoothing fits simple exponential smoothing, choosing the
+// smoothing factor that minimises the one-step squared error
+func fitExponentialSmoothing(history []float64, horizon int) (forecastFit, error) {
+	if len(history) < 2 {
+		return forecastFit{}, ErrInsufficientHistory
+	}
+
+	smooth := func(alpha float64) (float64, float64) {
+		level, squared := history[0], 0.0
+		for _, value := range history[1:] {
+			diff := value - level
+			squared += diff * diff
+			level += alpha * diff
+		}
+		return level, squared
+	}
+
+	bestAlpha, bestLevel, bestSquared := 0.0, 0.0, math.Inf(1)
+	for alpha := forecastGridStep; alpha < 1; alpha += forecastGridStep {
+		level, squared := smooth(alpha)
+		if squared < bestSquared {
+			bestAlpha, bestLevel, bestSquared = alpha, level, squared
+		}
+	}
+
+	return forecastFit{
+		params:   map[string]float64{"alpha": round(bestAlpha)},
+		stdDev:   rootMean(bestSquared, len(history)-1),
+		forecast: constantForecast(bestLevel, horizon),
+	}, nil
+}
+
+// fitHoltWinters fits additive Holt-Winters with weekly seasonality, choosing the
+// level, trend and seasonal factors that minimise the one-step squared error.
+// It needs at least two full seasons of history.
+func fitHoltWinters(history []float64, horizon int) (forecastFit, error) {
+	m := forecastSeasonLength
+	if len(history) < 2*m {
+		return forecastFit{}, ErrInsufficientHistory
+	}
+
+	var first, second float64
+	for i := 0; i < m; i++ {
+		first += history[i]
+		second += history[m+i]
+	}
+	first /= float64(m)
+	second /= float64(m)
+
+	run := func(alpha, beta, gamma float64) (float64, float64, []float64, float64) {
+		level, trend := first, (second-first)/float64(m)
+		seasonal := make([]float64, m)
+		for i := 0; i < m; i++ {
+			seasonal[i] = history[i] - first
+		}
+
+		squared := 0.0
+		for t := m; t < len(history); t++ {
+			s := seasonal[t%m]
+			diff := history[t] - (level + trend + s)
+			squared += diff * diff
+
+			previous := level
+			level = alpha*(history[t]-s) + (1-alpha)*(level+trend)
+			trend = beta*(level-previous) + (1-beta)*trend
+			seasonal[t%m] = gamma*(history[t]-level) + (1-gamma)*s
+		}
+		return level, trend, seasonal, squared
+	}
+
+	var best struct {
+		alpha, beta, gamma, level, trend, squared float64
+		seasonal                                  []float64
+	}
+	best.squared = math.Inf(1)
+	for alpha := forecastSeasonalGridStep; alpha < 1; alpha += forecastSeasonalGridStep {
+		for beta := 0.0; beta < 1; beta += forecastSeasonalGridStep {
+			for gamma := 0.0; gamma < 1; gamma += forecastSeasonalGridStep {
+				level, trend, seasonal, squared := run(alpha, beta, gamma)
+				if squared < best.squared {
+					best.alpha, best.beta, best.gamma = alpha, beta, gamma
+					best.level, best.trend, best.seasonal, best.squared = level, trend, seasonal, squared
+				}
+			}
+		}
+	}
+
+	forecast := make([]float64, horizon)
+	for h := range forecast {
+		forecast[h] = best.level + float64(h+1)*best.trend + best.seasonal[(len(history)+h)%m]
+	}
+
+	return forecastFit{
+		params: map[string]float64{
+			"alpha":         round(best.alpha),
+			"beta":          round(best.beta),
+			"gamma":         round(best.gamma),
+			"season_length": float64(m),
+		},
+		stdDev:   rootMean(best.squared, len(history)-m),
+		forecast: forecast,
+	}, nil
+}
+
+// constantForecast repeats a level over the horizon
+func constantForecast(level float64, horizon int) []float64 {
+	forecast := make([]float64, horizon)
+	for h := range forecast {
+		forecast[h] = level
+	}
+	return forecast
+}
+
+// rootMean returns the square root of the mean of a sum of squares
+func rootMean(squared float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Sqrt(squared / float64(count))
+}
+
+// round trims grid search noise from a fitted parameter
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
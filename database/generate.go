@@ -0,0 +1,459 @@
+// database/generate.go
+package database
+
+import (
+	"errors"
+	"fmt"
+	"inventory_system/models"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ErrDatabaseNotEmpty is returned when sample data would be mixed with real records
+var ErrDatabaseNotEmpty = errors.New("the database already has products")
+
+// GenerateOptions sizes the sample data and fixes the seed it is drawn from
+type GenerateOptions struct {
+	Products  int
+	Locations int
+	Orders    int
+	// Days of order history, ending on the day before End
+	Days int
+	End  time.Time
+	Seed int64
+	// BatchSize is the number of rows written per INSERT statement
+	BatchSize int
+	// Progress, when set, is called with the number of orders written so far
+	Progress func(orders int)
+}
+
+// GenerateResult counts the records written
+type GenerateResult struct {
+	Products  int
+	Locations int
+	Orders    int
+	Receipts  int
+}
+
+// generatedCategory describes how a category sells: its share of orders, the
+// day of the year its sales peak and how strongly, and its price range
+type generatedCategory struct {
+	Name      string
+	Share     float64
+	PeakDay   int
+	Amplitude float64
+	MinPrice  float64
+	MaxPrice  float64
+	Items     []string
+}
+
+var generatedCategories = []generatedCategory{
+	{"Electronics", 0.30, 335, 0.6, 25, 1500, []string{"Laptop", "Smartphone", "Headphones", "Tablet", "Monitor", "Speaker", "Camera", "Smartwatch"}},
+	{"Apparel", 0.25, 190, 0.3, 8, 120, []string{"T-shirt", "Jeans", "Jacket", "Sweater", "Dress", "Shorts", "Hoodie", "Scarf"}},
+	{"Footwear", 0.15, 240, 0.35, 20, 200, []string{"Sneakers", "Boots", "Sandals", "Loafers", "Running Shoes", "Slippers"}},
+	{"Furniture", 0.12, 90, 0.2, 40, 900, []string{"Coffee Table", "Desk Chair", "Bookshelf", "Sofa", "Bed Frame", "Desk", "Wardrobe"}},
+	{"Appliances", 0.18, 350, 0.4, 15, 600, []string{"Blender", "Toaster", "Microwave", "Kettle", "Vacuum", "Coffee Maker", "Air Fryer"}},
+}
+
+var generatedSeries = []string{"Classic", "Pro", "Lite", "Max", "Plus", "Mini", "Eco", "Prime"}
+
+// weekdayFactors scale the orders of each day of the week, Sunday first
+var weekdayFactors = [7]float64{1.2, 0.85, 0.9, 0.95, 1.0, 1.15, 1.35}
+
+// generatedProduct holds the figures orders for a written product are priced from
+type generatedProduct struct {
+	ID       uint
+	Price    float64
+	UnitCost float64
+}
+
+// pairKey identifies the stock of a product at a location
+type pairKey struct {
+	product  int
+	location int
+}
+
+// GenerateSampleData fills an empty database with products across categories,
+// locations with a receiving bin each, and a history of orders with weekly and
+// seasonal patterns. Stock is replenished monthly ahead of the orders so it
+// never runs out, and every change is recorded in the movement ledger. The
+// same options always produce the same data.
+func GenerateSampleData(db *gorm.DB, options GenerateOptions) (GenerateResult, error) {
+	var result GenerateResult
+	if options.Products <= 0 || options.Locations <= 0 || options.Orders < 0 || options.Days <= 0 {
+		return result, errors.New("products, locations and days must be positive")
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 1000
+	}
+
+	var count int64
+	if err := db.Model(&models.Product{}).Count(&count).Error; err != nil {
+		return result, err
+	}
+	if count > 0 {
+		return result, ErrDatabaseNotEmpty
+	}
+
+	// Statements of a thousand rows are too large to be worth logging
+	db = db.Session(&gorm.Session{Logger: db.Logger.LogMode(logger.Silent), CreateBatchSize: options.BatchSize})
+	rng := rand.New(rand.NewSource(options.Seed))
+	end := time.Date(options.End.Year(), options.End.Month(), options.End.Day(), 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -options.Days)
+
+	products, byCategory, err := generateProducts(db, rng, options.Products, start)
+	if err != nil {
+		return result, err
+	}
+	result.Products = len(products)
+
+	locations, bins, err := generateLocations(db, options.Locations)
+	if err != nil {
+		return result, err
+	}
+	result.Locations = len(locations)
+
+	// Popularity falls away within each category, and some locations are busier
+	popularity := make([][]float64, len(generatedCategories))
+	for c, members := range byCategory {
+		popularity[c] = cumulative(len(members), func(rank int) float64 { return 1 / math.Pow(float64(rank+1), 0.8) })
+	}
+	locationWeights := cumulative(len(locations), func(int) float64 { return 0.5 + rng.Float64() })
+	// Each product is stocked to a safety level at each location after replenishment
+	safety := make(map[pairKey]int)
+	for p := range products {
+		for l, location := range locations {
+			base := 10 + rng.Intn(30)
+			if isWarehouse(location) {
+				base *= 2
+			}
+			safety[pairKey{p, l}] = base
+		}
+	}
+
+	dailyOrders := spreadOrders(options.Orders, start, options.Days, rng)
+	stock := make(map[pairKey]int)
+	var layers []models.CostLayer
+
+	// Orders are generated a month at a time; the month's receipts arrive on its
+	// first day, topping each product and location up to its sales plus safety stock
+	for monthStart := start; monthStart.Before(end); monthStart = monthStart.AddDate(0, 1, 0) {
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		if monthEnd.After(end) {
+			monthEnd = end
+		}
+
+		var orders []models.Order
+		var orderPairs []pairKey
+		sold := make(map[pairKey]int)
+		for day := monthStart; day.Before(monthEnd); day = day.AddDate(0, 0, 1) {
+			n := dailyOrders[int(day.Sub(start).Hours()/24)]
+			categoryWeights := cumulative(len(generatedCategories), func(c int) float64 {
+				return generatedCategories[c].Share * seasonality(generatedCategories[c], day)
+			})
+			for i := 0; i < n; i++ {
+				c := pick(rng, categoryWeights)
+				key := pairKey{byCategory[c][pick(rng, popularity[c])], pick(rng, locationWeights)}
+				product := products[key.product]
+				quantity := 1 + rng.Intn(3)
+				// An occasional bulk order
+				if rng.Intn(50) == 0 {
+					quantity += 5 + rng.Intn(20)
+				}
+
+				orders = append(orders, models.Order{
+					ProductID:   product.ID,
+					Quantity:    quantity,
+					Location:    locations[key.location],
+					OrderDate:   day.Add(time.Duration(8*3600+rng.Intn(13*3600)) * time.Second),
+					TotalPrice:  product.Price * float64(quantity),
+					CostOfGoods: product.UnitCost * float64(quantity),
+				})
+				orderPairs = append(orderPairs, key)
+				sold[key] += quantity
+			}
+		}
+
+		var receipts []models.StockMovement
+		for p, product := range products {
+			for l, location := range locations {
+				key := pairKey{p, l}
+				quantity := sold[key] + safety[key] - stock[key]
+				if quantity <= 0 {
+					continue
+				}
+				stock[key] += quantity
+				binID := bins[l]
+				receipts = append(receipts, models.StockMovement{
+					ProductID: product.ID,
+					Location:  location,
+					BinID:     &binID,
+					Quantity:  quantity,
+					Type:      models.MovementReceipt,
+					Reference: "sample-data",
+					UnitCost:  product.UnitCost,
+					CostValue: product.UnitCost * float64(quantity),
+					CreatedAt: monthStart,
+				})
+			}
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if len(receipts) > 0 {
+				if err := tx.Create(&receipts).Error; err != nil {
+					return fmt.Errorf("writing receipts: %w", err)
+				}
+			}
+			if len(orders) == 0 {
+				return nil
+			}
+			if err := tx.Create(&orders).Error; err != nil {
+				return fmt.Errorf("writing orders: %w", err)
+			}
+
+			issues := make([]models.StockMovement, len(orders))
+			for i, order := range orders {
+				stock[orderPairs[i]] -= order.Quantity
+				issues[i] = models.StockMovement{
+					ProductID: order.ProductID,
+					Location:  order.Location,
+					Quantity:  -order.Quantity,
+					Type:      models.MovementOrder,
+					Reference: fmt.Sprintf("order:%d", order.OrderID),
+					UnitCost:  products[orderPairs[i].product].UnitCost,
+					CostValue: -order.CostOfGoods,
+					CreatedAt: order.OrderDate,
+				}
+			}
+			if err := tx.Create(&issues).Error; err != nil {
+				return fmt.Errorf("writing order movements: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, receipt := range receipts {
+			layers = append(layers, models.CostLayer{
+				ProductID:  receipt.ProductID,
+				MovementID: receipt.ID,
+				Quantity:   receipt.Quantity,
+				UnitCost:   receipt.UnitCost,
+				ReceivedAt: receipt.CreatedAt,
+			})
+		}
+		result.Orders += len(orders)
+		result.Receipts += len(receipts)
+		if options.Progress != nil {
+			options.Progress(result.Orders)
+		}
+	}
+
+	return result, db.Transaction(func(tx *gorm.DB) error {
+		return writeClosingStock(tx, products, locations, bins, stock, layers)
+	})
+}
+
+// writeClosingStock writes the stock left after the generated orders to the
+// bins and inventory, and the cost layers still holding it, newest first
+func writeClosingStock(tx *gorm.DB, products []generatedProduct, locations []string, bins []uint, stock map[pairKey]int, layers []models.CostLayer) error {
+	remaining := make(map[uint]int)
+	var binStocks []models.BinStock
+	var inventories []models.Inventory
+	for p, product := range products {
+		for l, location := range locations {
+			quantity := stock[pairKey{p, l}]
+			if quantity <= 0 {
+				continue
+			}
+			remaining[product.ID] += quantity
+			binStocks = append(binStocks, models.BinStock{ProductID: product.ID, BinID: bins[l], Quantity: quantity})
+			inventories = append(inventories, models.Inventory{ProductID: product.ID, Location: location, Quantity: quantity})
+		}
+	}
+
+	// FIFO has consumed the oldest layers, so what is left sits in the newest
+	for i := len(layers) - 1; i >= 0; i-- {
+		take := layers[i].Quantity
+		if left := remaining[layers[i].ProductID]; left < take {
+			take = left
+		}
+		layers[i].Remaining = take
+		remaining[layers[i].ProductID] -= take
+	}
+
+	if len(binStocks) > 0 {
+		if err := tx.Create(&binStocks).Error; err != nil {
+			return fmt.Errorf("writing bin stock: %w", err)
+		}
+		if err := tx.Create(&inventories).Error; err != nil {
+			return fmt.Errorf("writing inventory: %w", err)
+		}
+	}
+	if len(layers) > 0 {
+		if err := tx.Create(&layers).Error; err != nil {
+			return fmt.Errorf("writing cost layers: %w", err)
+		}
+	}
+	return nil
+}
+
+// generateProducts writes n products spread over the categories by their
+// share and returns them with the indexes of each category's products
+func generateProducts(db *gorm.DB, rng *rand.Rand, n int, createdAt time.Time) ([]generatedProduct, [][]int, error) {
+	shares := cumulative(len(generatedCategories), func(c int) float64 { return generatedCategories[c].Share })
+	rows := make([]models.Product, n)
+	categories := make([]int, n)
+	for i := range rows {
+		// Every category gets a product before the rest are drawn by share
+		c := i
+		if i >= len(generatedCategories) {
+			c = pick(rng, shares)
+		}
+		category := generatedCategories[c]
+		// Prices are spread evenly on a log scale across the category's range
+		price := math.Round(category.MinPrice*math.Pow(category.MaxPrice/category.MinPrice, rng.Float64())*100)/100 - 0.01
+		cost := math.Round(price*(0.45+0.25*rng.Float64())*100) / 100
+		item := category.Items[rng.Intn(len(category.Items))]
+		series := generatedSeries[rng.Intn(len(generatedSeries))]
+
+		categories[i] = c
+		rows[i] = models.Product{
+			Name:         fmt.Sprintf("%s %s %d", item, series, i+1),
+			Description:  fmt.Sprintf("%s %s from the %s range", series, item, category.Name),
+			Price:        price,
+			Category:     category.Name,
+			StandardCost: cost,
+			AverageCost:  cost,
+			CreatedAt:    createdAt,
+			UpdatedAt:    createdAt,
+		}
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("writing products: %w", err)
+	}
+
+	products := make([]generatedProduct, n)
+	byCategory := make([][]int, len(generatedCategories))
+	for i, row := range rows {
+		products[i] = generatedProduct{ID: row.ID, Price: row.Price, UnitCost: row.StandardCost}
+		byCategory[categories[i]] = append(byCategory[categories[i]], i)
+	}
+	// A category may be empty when there are fewer products than categories
+	for c := range byCategory {
+		if len(byCategory[c]) == 0 {
+			byCategory[c] = byCategory[0]
+		}
+	}
+	return products, byCategory, nil
+}
+
+// generateLocations names n locations, a third of them warehouses, and writes a
+// receiving bin for each. The first is always the default location.
+func generateLocations(db *gorm.DB, n int) ([]string, []uint, error) {
+	warehouses := (n + 2) / 3
+	locations := make([]string, n)
+	rows := make([]models.Bin, n)
+	for i := range locations {
+		if i < warehouses {
+			locations[i] = "Warehouse " + warehouseLetters(i)
+		} else {
+			locations[i] = fmt.Sprintf("Store %d", i-warehouses+1)
+		}
+		rows[i] = models.Bin{Location: locations[i], Code: models.ReceivingBin}
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("writing bins: %w", err)
+	}
+
+	bins := make([]uint, n)
+	for i, row := range rows {
+		bins[i] = row.ID
+	}
+	return locations, bins, nil
+}
+
+// warehouseLetters names warehouses A to Z, then AA, AB and so on
+func warehouseLetters(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return warehouseLetters(i/26-1) + string(rune('A'+i%26))
+}
+
+// spreadOrders divides the orders among the days in proportion to each day's
+// weekday, seasonal and trend weight, handing out the remainders largest first
+func spreadOrders(orders int, start time.Time, days int, rng *rand.Rand) []int {
+	weights := make([]float64, days)
+	total := 0.0
+	for d := range weights {
+		day := start.AddDate(0, 0, d)
+		season := 0.0
+		for _, category := range generatedCategories {
+			season += category.Share * seasonality(category, day)
+		}
+		// Sales grow by a fifth over the period, with some day-to-day noise
+		trend := 1 + 0.2*float64(d)/float64(days)
+		weights[d] = weekdayFactors[day.Weekday()] * season * trend * (0.9 + 0.2*rng.Float64())
+		total += weights[d]
+	}
+
+	counts := make([]int, days)
+	remainders := make([]int, days)
+	assigned := 0
+	for d, weight := range weights {
+		share := float64(orders) * weight / total
+		counts[d] = int(share)
+		assigned += counts[d]
+		remainders[d] = d
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		a := float64(orders)*weights[remainders[i]]/total - float64(counts[remainders[i]])
+		b := float64(orders)*weights[remainders[j]]/total - float64(counts[remainders[j]])
+		return a > b
+	})
+	for i := 0; assigned < orders; i++ {
+		counts[remainders[i%days]]++
+		assigned++
+	}
+	return counts
+}
+
+// seasonality is a category's sales multiplier on a day: highest on its peak
+// day and lowest half a year away
+func seasonality(category generatedCategory, day time.Time) float64 {
+	distance := float64(day.YearDay()-category.PeakDay) / 365.25
+	return 1 + category.Amplitude*math.Cos(2*math.Pi*distance)
+}
+
+// isWarehouse reports whether a generated location is a warehouse
+func isWarehouse(location string) bool {
+	return strings.HasPrefix(location, "Warehouse ")
+}
+
+// cumulative returns the running totals of n weights, for use with pick
+func cumulative(n int, weight func(i int) float64) []float64 {
+	totals := make([]float64, n)
+	sum := 0.0
+	for i := range totals {
+		sum += weight(i)
+		totals[i] = sum
+	}
+	return totals
+}
+
+// pick draws an index with probability proportional to its weight
+func pick(rng *rand.Rand, totals []float64) int {
+	i := sort.SearchFloat64s(totals, rng.Float64()*totals[len(totals)-1])
+	if i >= len(totals) {
+		i = len(totals) - 1
+	}
+	return i
+}
@@ -0,0 +1,66 @@
+// database/history.go
+package database
+
+import (
+	"inventory_system/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// balancesAsOf sums the stock movements of every product and location up to a
+// point in time, leaving out balances that had returned to zero
+func balancesAsOf(db *gorm.DB, asOf time.Time) *gorm.DB {
+	return db.Table("stock_movements").
+		Select("product_id, location, SUM(quantity) as quantity").
+		Where("created_at <= ?", asOf).
+		Group("product_id, location").
+		Having("SUM(quantity) <> 0")
+}
+
+// GetInventoryAsOf rebuilds the inventory as it stood at a point in time from the
+// stock movement ledger, optionally for one product or location. Stock held before
+// the ledger was introduced is only known from its opening balance movement onwards.
+func GetInventoryAsOf(db *gorm.DB, asOf time.Time, productID uint, location string) ([]models.Inventory, error) {
+	var balances []struct {
+		ProductID uint
+		Location  string
+		Quantity  int
+	}
+	query := db.Table("(?) as balances", balancesAsOf(db, asOf))
+	if productID != 0 {
+		query = query.Where("product_id = ?", productID)
+	}
+	if location != "" {
+		query = query.Where("location = ?", location)
+	}
+	if err := query.Order("product_id, location").Scan(&balances).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(balances))
+	for _, balance := range balances {
+		ids = append(ids, balance.ProductID)
+	}
+	var products []models.Product
+	if len(ids) > 0 {
+		if err := db.Where("id IN ?", ids).Find(&products).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	inventories := make([]models.Inventory, 0, len(balances))
+	for _, balance := range balances {
+		inventories = append(inventories, models.Inventory{
+			ProductID: balance.ProductID,
+			Product:   byID[balance.ProductID],
+			Location:  balance.Location,
+			Quantity:  balance.Quantity,
+		})
+	}
+	return inventories, nil
+}
@@ -0,0 +1,133 @@
+// database/margins.go
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GrossMarginRow holds revenue, cost of goods sold and margin for one group of orders
+type GrossMarginRow struct {
+	GroupKey string `json:"group"`
+	// ProductID is set when grouping by product, since product names need not be unique
+	ProductID     uint    `json:"product_id,omitempty"`
+	OrderCount    int     `json:"order_count"`
+	UnitsSold     int     `json:"units_sold"`
+	Revenue       float64 `json:"revenue"`
+	CostOfGoods   float64 `json:"cost_of_goods"`
+	GrossMargin   float64 `json:"gross_margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+// ProductMarginRow holds the margin of one product and its share of the total margin
+type ProductMarginRow struct {
+	ProductID         uint    `json:"product_id"`
+	Name              string  `json:"name"`
+	Category          string  `json:"category"`
+	UnitsSold         int     `json:"units_sold"`
+	Revenue           float64 `json:"revenue"`
+	CostOfGoods       float64 `json:"cost_of_goods"`
+	GrossMargin       float64 `json:"gross_margin"`
+	MarginPercent     float64 `json:"margin_percent"`
+	ContributionShare float64 `json:"contribution_share"`
+}
+
+// marginGroups maps a group-by option to the expression orders are grouped on,
+// which for months depends on the database
+var marginGroups = map[string]func(db *gorm.DB) string{
+	"product":  func(*gorm.DB) string { return "products.name" },
+	"category": func(*gorm.DB) string { return "products.category" },
+	"location": func(*gorm.DB) string { return "orders.location" },
+	"period":   func(db *gorm.DB) string { return monthExpression(db, "orders.order_date") },
+}
+
+// IsValidMarginGroup reports whether gross margin can be grouped by the given option
+func IsValidMarginGroup(groupBy string) bool {
+	_, ok := marginGroups[groupBy]
+	return ok
+}
+
+// GetGrossMargin calculates gross margin grouped by product, category, location or
+// month, using the cost of goods recorded on each order
+func GetGrossMargin(db *gorm.DB, groupBy string, from, to *time.Time) ([]GrossMarginRow, error) {
+	group, ok := marginGroups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown margin group %q", groupBy)
+	}
+	expression := group(db)
+	key, grouping, order := expression+" as group_key", expression, "group_key"
+	if groupBy == "product" {
+		key, grouping, order = "products.id as product_id, "+key, "products.id, "+grouping, "group_key, product_id"
+	}
+
+	var results []GrossMarginRow
+	query := db.Table("orders").
+		Select(key + ", COUNT(orders.order_id) as order_count, SUM(orders.quantity) as units_sold, " +
+			"SUM(orders.total_price) as revenue, SUM(orders.cost_of_goods) as cost_of_goods").
+		Joins("JOIN products ON orders.product_id = products.id")
+	query = orderDateRange(query, from, to)
+
+	err := query.Group(grouping).Order(order).Scan(&results).Error
+	for i := range results {
+		results[i].GrossMargin = results[i].Revenue - results[i].CostOfGoods
+		results[i].MarginPercent = marginPercent(results[i].GrossMargin, results[i].Revenue)
+	}
+
+	return results, err
+}
+
+// GetProductsByMargin ranks products by their contribution to gross margin
+func GetProductsByMargin(db *gorm.DB, limit int, from, to *time.Time) ([]ProductMarginRow, error) {
+	var results []ProductMarginRow
+	query := db.Table("orders").
+		Select("products.id as product_id, products.name, products.category, SUM(orders.quantity) as units_sold, " +
+			"SUM(orders.total_price) as revenue, SUM(orders.cost_of_goods) as cost_of_goods, " +
+			"SUM(orders.total_price) - SUM(orders.cost_of_goods) as gross_margin").
+		Joins("JOIN products ON orders.product_id = products.id")
+	query = orderDateRange(query, from, to)
+
+	err := query.Group("products.id, products.name, products.category").
+		Order("gross_margin DESC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Contribution is measured against every product, not just the ones returned
+	total := 0.0
+	for _, row := range results {
+		total += row.GrossMargin
+	}
+	for i := range results {
+		results[i].MarginPercent = marginPercent(results[i].GrossMargin, results[i].Revenue)
+		if total != 0 {
+			results[i].ContributionShare = results[i].GrossMargin / total * 100
+		}
+	}
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// orderDateRange restricts a query on orders to an optional date range
+func orderDateRange(query *gorm.DB, from, to *time.Time) *gorm.DB {
+	if from != nil {
+		query = query.Where("orders.order_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("orders.order_date < ?", *to)
+	}
+	return query
+}
+
+// marginPercent returns margin as a percentage of revenue
+func marginPercent(margin, revenue float64) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return margin / revenue * 100
+}
@@ -0,0 +1,312 @@
+// database/migrate.go
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles holds the versioned schema changes. Each is named
+// NNNN_name.up.sql and NNNN_name.down.sql; a file with the driver before the
+// extension, such as NNNN_name.up.postgres.sql, replaces the portable one for
+// that database.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)(?:\.(mysql|postgres|sqlite))?\.sql$`)
+
+// ErrSchemaNotMigrated is returned when the database is behind the migrations
+// this build knows about
+var ErrSchemaNotMigrated = errors.New("database schema is not migrated")
+
+// Migration is one versioned schema change with the scripts that apply and
+// revert it on a particular database
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState reports whether a migration has been applied and when
+type MigrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// schemaMigration is a row of the schema_migrations table
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// Migrations returns the migrations for a database driver in version order
+func Migrations(driver string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	// Driver-specific scripts win over portable ones whatever order they are read in
+	specific := make(map[string]bool)
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		fileDriver := match[4]
+		if fileDriver != "" && fileDriver != driver {
+			continue
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+
+		key := match[1] + "." + match[3]
+		if specific[key] && fileDriver == "" {
+			continue
+		}
+		specific[key] = fileDriver != ""
+
+		script, err := fs.ReadFile(migrationFiles, path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration in order and returns the ones applied
+func MigrateUp(db *gorm.DB) ([]Migration, error) {
+	migrations, applied, err := loadMigrationState(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if migration.Version == 1 {
+				if err := adoptBaselineSchema(tx); err != nil {
+					return err
+				}
+			}
+			if err := execScript(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// MigrateDown reverts the most recently applied migrations, newest first, and
+// returns the ones reverted
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, applied, err := loadMigrationState(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{Version: migration.Version}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// GetMigrationStatus lists every known migration and when it was applied
+func GetMigrationStatus(db *gorm.DB) ([]MigrationState, error) {
+	migrations, applied, err := loadMigrationState(db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, len(migrations))
+	for i, migration := range migrations {
+		states[i] = MigrationState{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			states[i].AppliedAt = &appliedAt
+		}
+	}
+	return states, nil
+}
+
+// CheckMigrations returns ErrSchemaNotMigrated unless every migration has been applied
+func CheckMigrations(db *gorm.DB) error {
+	states, err := GetMigrationStatus(db)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, state := range states {
+		if state.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%04d_%s", state.Version, state.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending (%s)", ErrSchemaNotMigrated, len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// baselineColumns are the columns the initial schema has on the tables that
+// databases created before migrations, by AutoMigrate, already hold
+var baselineColumns = []struct {
+	table, column, definition string
+}{
+	{"products", "standard_cost", "DECIMAL(12, 4) NOT NULL DEFAULT 0"},
+	{"products", "average_cost", "DECIMAL(12, 4) NOT NULL DEFAULT 0"},
+	{"products", "abc_class", "VARCHAR(1)"},
+	{"products", "xyz_class", "VARCHAR(1)"},
+	{"products", "classified_at", "%s NULL"},
+	{"orders", "location", "VARCHAR(100) NOT NULL DEFAULT 'Warehouse A'"},
+	{"orders", "cost_of_goods", "DECIMAL(12, 2) NOT NULL DEFAULT 0"},
+}
+
+// baselineIndexes are the indexes the initial schema has on those tables
+var baselineIndexes = []struct {
+	table, name, columns string
+}{
+	{"products", "idx_products_abc_class", "abc_class"},
+	{"products", "idx_products_xyz_class", "xyz_class"},
+	{"orders", "idx_orders_date", "order_date"},
+}
+
+// adoptBaselineSchema brings the products and orders tables of a database
+// created before migrations up to the initial schema, which only creates the
+// tables that do not exist yet. Databases without these tables are left alone.
+func adoptBaselineSchema(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	for _, column := range baselineColumns {
+		if !migrator.HasTable(column.table) || migrator.HasColumn(column.table, column.column) {
+			continue
+		}
+		definition := column.definition
+		if strings.Contains(definition, "%s") {
+			definition = fmt.Sprintf(definition, timestampType(tx))
+		}
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", column.table, column.column, definition)).Error; err != nil {
+			return fmt.Errorf("adding %s.%s: %w", column.table, column.column, err)
+		}
+	}
+	for _, index := range baselineIndexes {
+		if !migrator.HasTable(index.table) || migrator.HasIndex(index.table, index.name) {
+			continue
+		}
+		if err := tx.Exec(fmt.Sprintf("CREATE INDEX %s ON %s (%s)", index.name, index.table, index.columns)).Error; err != nil {
+			return fmt.Errorf("creating index %s: %w", index.name, err)
+		}
+	}
+	return nil
+}
+
+// loadMigrationState reads the migrations for the database and the versions
+// already applied, creating the schema_migrations table on first use
+func loadMigrationState(db *gorm.DB) ([]Migration, map[int]time.Time, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (" +
+		"version BIGINT NOT NULL PRIMARY KEY, " +
+		"name VARCHAR(255) NOT NULL, " +
+		"applied_at TIMESTAMP NOT NULL)").Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	applied := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return migrations, applied, nil
+}
+
+// execScript runs the statements of a migration script one at a time, since not
+// every driver accepts several statements in one call. Statements end with a
+// semicolon at the end of a line.
+func execScript(tx *gorm.DB, script string) error {
+	var statement strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			if err := tx.Exec(statement.String()).Error; err != nil {
+				return err
+			}
+			statement.Reset()
+		}
+	}
+	if strings.TrimSpace(statement.String()) != "" {
+		return tx.Exec(statement.String()).Error
+	}
+	return nil
+}
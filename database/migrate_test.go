@@ -0,0 +1,108 @@
+// database/migrate_test.go
+package database
+
+import (
+	"context"
+	"inventory_system/config"
+	"inventory_system/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// The tables as the baseline created them with AutoMigrate, before migrations
+type baselineProduct struct {
+	ID          uint    `gorm:"primaryKey;type:int unsigned"`
+	Name        string  `gorm:"size:100;not null"`
+	Description string  `gorm:"type:text"`
+	Price       float64 `gorm:"type:decimal(10,2);not null;check:price >= 0"`
+	Category    string  `gorm:"size:50;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ImagePath   string `gorm:"size:255"`
+}
+
+func (baselineProduct) TableName() string { return "products" }
+
+type baselineInventory struct {
+	ProductID uint   `gorm:"primaryKey;type:int unsigned"`
+	Quantity  int    `gorm:"not null;default:0"`
+	Location  string `gorm:"size:100;not null;primaryKey"`
+}
+
+func (baselineInventory) TableName() string { return "inventories" }
+
+type baselineOrder struct {
+	OrderID    uint      `gorm:"primaryKey;type:int unsigned"`
+	ProductID  uint      `gorm:"type:int unsigned;not null"`
+	Quantity   int       `gorm:"not null;check:quantity > 0"`
+	OrderDate  time.Time `gorm:"not null"`
+	TotalPrice float64   `gorm:"type:decimal(10,2);not null"`
+}
+
+func (baselineOrder) TableName() string { return "orders" }
+
+func TestMigrateUpAdoptsBaselineSchema(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.Path = filepath.Join(t.TempDir(), "baseline.db")
+	cfg.Log.Level = "silent"
+
+	db, err := Connect(&cfg)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	// Build and fill the baseline schema without the tenant callbacks
+	raw := db.WithContext(AllTenants(context.Background()))
+	if err := raw.AutoMigrate(&baselineProduct{}, &baselineInventory{}, &baselineOrder{}); err != nil {
+		t.Fatalf("creating baseline schema: %v", err)
+	}
+	// SQLite only generates IDs for INTEGER primary keys, so they are given here
+	product := baselineProduct{ID: 1, Name: "Desk Lamp", Category: "Furniture", Price: 25}
+	if err := raw.Create(&product).Error; err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	if err := raw.Create(&baselineInventory{ProductID: product.ID, Location: "Store 1", Quantity: 7}).Error; err != nil {
+		t.Fatalf("creating inventory: %v", err)
+	}
+	if err := raw.Create(&baselineOrder{OrderID: 1, ProductID: product.ID, Quantity: 2, OrderDate: time.Now(), TotalPrice: 50}).Error; err != nil {
+		t.Fatalf("creating order: %v", err)
+	}
+
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("migrating a baseline database: %v", err)
+	}
+
+	migrator := db.Migrator()
+	for _, column := range baselineColumns {
+		if !migrator.HasColumn(column.table, column.column) {
+			t.Errorf("%s.%s is missing after migrating", column.table, column.column)
+		}
+	}
+
+	tenant := db.WithContext(WithTenant(context.Background(), DefaultTenantID))
+	var order models.Order
+	if err := tenant.First(&order).Error; err != nil {
+		t.Fatalf("loading the baseline order: %v", err)
+	}
+	if order.Location != "Warehouse A" || order.CostOfGoods != 0 {
+		t.Errorf("baseline order has location %q and cost of goods %.2f, want Warehouse A and 0", order.Location, order.CostOfGoods)
+	}
+
+	// Startup backfills move the baseline stock into a bin with an opening balance
+	if err := BackfillBins(tenant); err != nil {
+		t.Fatalf("backfilling bins: %v", err)
+	}
+	if err := BackfillOpeningBalances(tenant); err != nil {
+		t.Fatalf("backfilling opening balances: %v", err)
+	}
+	if err := PickFromLocation(tenant, product.ID, "Store 1", 7); err != nil {
+		t.Errorf("picking the baseline stock: %v", err)
+	}
+}
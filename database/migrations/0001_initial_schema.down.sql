@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS report_deliveries;
+DROP TABLE IF EXISTS report_schedules;
+DROP TABLE IF EXISTS cost_layers;
+DROP TABLE IF EXISTS adjustments;
+DROP TABLE IF EXISTS count_lines;
+DROP TABLE IF EXISTS count_sessions;
+DROP TABLE IF EXISTS stock_movements;
+DROP TABLE IF EXISTS bin_stocks;
+DROP TABLE IF EXISTS bins;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS inventories;
+DROP TABLE IF EXISTS products;
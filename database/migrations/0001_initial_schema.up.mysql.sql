@@ -0,0 +1,165 @@
+-- Initial schema: products, stock, orders, bins, the movement ledger, cycle
+-- counts, adjustments, cost layers and report schedules
+
+CREATE TABLE IF NOT EXISTS products (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    category VARCHAR(50) NOT NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    image_path VARCHAR(255),
+    standard_cost DECIMAL(12, 4) NOT NULL DEFAULT 0,
+    average_cost DECIMAL(12, 4) NOT NULL DEFAULT 0,
+    abc_class VARCHAR(1),
+    xyz_class VARCHAR(1),
+    classified_at DATETIME(3) NULL,
+    INDEX idx_products_abc_class (abc_class),
+    INDEX idx_products_xyz_class (xyz_class)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS inventories (
+    product_id INT UNSIGNED NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    quantity BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, location),
+    CONSTRAINT fk_inventories_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS orders (
+    order_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id INT UNSIGNED NOT NULL,
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    location VARCHAR(100) NOT NULL DEFAULT 'Warehouse A',
+    order_date DATETIME(3) NOT NULL,
+    total_price DECIMAL(10, 2) NOT NULL,
+    cost_of_goods DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    INDEX idx_orders_date (order_date),
+    CONSTRAINT fk_orders_product FOREIGN KEY (product_id) REFERENCES products(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS bins (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    location VARCHAR(100) NOT NULL,
+    zone VARCHAR(20),
+    aisle VARCHAR(20),
+    shelf VARCHAR(20),
+    code VARCHAR(50) NOT NULL,
+    UNIQUE INDEX idx_bins_location_code (location, code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS bin_stocks (
+    product_id INT UNSIGNED NOT NULL,
+    bin_id INT UNSIGNED NOT NULL,
+    quantity BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, bin_id),
+    CONSTRAINT fk_bin_stocks_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_bin_stocks_bin FOREIGN KEY (bin_id) REFERENCES bins(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id INT UNSIGNED NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    bin_id INT UNSIGNED,
+    quantity BIGINT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    reason VARCHAR(20),
+    reference VARCHAR(100),
+    count_session_id INT UNSIGNED,
+    unit_cost DECIMAL(12, 4) NOT NULL DEFAULT 0,
+    cost_value DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NULL,
+    INDEX idx_movements_product_location (product_id, location),
+    INDEX idx_stock_movements_count_session_id (count_session_id),
+    INDEX idx_stock_movements_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS count_sessions (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    location VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    note TEXT,
+    created_at DATETIME(3) NULL,
+    closed_at DATETIME(3) NULL,
+    INDEX idx_count_sessions_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS count_lines (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    session_id INT UNSIGNED NOT NULL,
+    product_id INT UNSIGNED NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    expected_quantity BIGINT NOT NULL,
+    counted_quantity BIGINT,
+    counted_at DATETIME(3) NULL,
+    INDEX idx_count_lines_session_id (session_id),
+    CONSTRAINT fk_count_sessions_lines FOREIGN KEY (session_id) REFERENCES count_sessions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_count_lines_product FOREIGN KEY (product_id) REFERENCES products(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS adjustments (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id INT UNSIGNED NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    bin_id INT UNSIGNED,
+    action VARCHAR(10) NOT NULL,
+    quantity BIGINT NOT NULL,
+    unit_cost DECIMAL(12, 4),
+    value DECIMAL(12, 2) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    note TEXT,
+    status VARCHAR(20) NOT NULL,
+    decision_note TEXT,
+    created_at DATETIME(3) NULL,
+    decided_at DATETIME(3) NULL,
+    INDEX idx_adjustments_status (status),
+    CONSTRAINT fk_adjustments_product FOREIGN KEY (product_id) REFERENCES products(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id INT UNSIGNED NOT NULL,
+    movement_id INT UNSIGNED,
+    quantity BIGINT NOT NULL,
+    remaining BIGINT NOT NULL,
+    unit_cost DECIMAL(12, 4) NOT NULL,
+    received_at DATETIME(3) NOT NULL,
+    INDEX idx_cost_layers_product_received (product_id, received_at),
+    CONSTRAINT fk_cost_layers_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS report_schedules (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    report VARCHAR(50) NOT NULL,
+    params TEXT,
+    cron VARCHAR(100) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    sink VARCHAR(20) NOT NULL,
+    target VARCHAR(500),
+    active BOOLEAN NOT NULL,
+    next_run_at DATETIME(3) NULL,
+    last_run_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    INDEX idx_report_schedules_next_run_at (next_run_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS report_deliveries (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    schedule_id INT UNSIGNED NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    sink VARCHAR(20) NOT NULL,
+    destination VARCHAR(500),
+    file_name VARCHAR(255),
+    size BIGINT,
+    `rows` BIGINT,
+    error TEXT,
+    started_at DATETIME(3) NULL,
+    finished_at DATETIME(3) NULL,
+    INDEX idx_report_deliveries_schedule_id (schedule_id),
+    INDEX idx_report_deliveries_started_at (started_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,165 @@
+-- Initial schema: products, stock, orders, bins, the movement ledger, cycle
+-- counts, adjustments, cost layers and report schedules
+
+CREATE TABLE IF NOT EXISTS products (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    price NUMERIC(10, 2) NOT NULL CHECK (price >= 0),
+    category VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NULL,
+    updated_at TIMESTAMPTZ NULL,
+    image_path VARCHAR(255),
+    standard_cost NUMERIC(12, 4) NOT NULL DEFAULT 0,
+    average_cost NUMERIC(12, 4) NOT NULL DEFAULT 0,
+    abc_class VARCHAR(1),
+    xyz_class VARCHAR(1),
+    classified_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_products_abc_class ON products (abc_class);
+CREATE INDEX IF NOT EXISTS idx_products_xyz_class ON products (xyz_class);
+
+CREATE TABLE IF NOT EXISTS inventories (
+    product_id INTEGER NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    quantity BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, location),
+    CONSTRAINT fk_inventories_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    order_id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    location VARCHAR(100) NOT NULL DEFAULT 'Warehouse A',
+    order_date TIMESTAMPTZ NOT NULL,
+    total_price NUMERIC(10, 2) NOT NULL,
+    cost_of_goods NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    CONSTRAINT fk_orders_product FOREIGN KEY (product_id) REFERENCES products(id)
+);
+CREATE INDEX IF NOT EXISTS idx_orders_date ON orders (order_date);
+
+CREATE TABLE IF NOT EXISTS bins (
+    id SERIAL PRIMARY KEY,
+    location VARCHAR(100) NOT NULL,
+    zone VARCHAR(20),
+    aisle VARCHAR(20),
+    shelf VARCHAR(20),
+    code VARCHAR(50) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bins_location_code ON bins (location, code);
+
+CREATE TABLE IF NOT EXISTS bin_stocks (
+    product_id INTEGER NOT NULL,
+    bin_id INTEGER NOT NULL,
+    quantity BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, bin_id),
+    CONSTRAINT fk_bin_stocks_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_bin_stocks_bin FOREIGN KEY (bin_id) REFERENCES bins(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    bin_id INTEGER,
+    quantity BIGINT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    reason VARCHAR(20),
+    reference VARCHAR(100),
+    count_session_id INTEGER,
+    unit_cost NUMERIC(12, 4) NOT NULL DEFAULT 0,
+    cost_value NUMERIC(14, 4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_movements_product_location ON stock_movements (product_id, location);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_count_session_id ON stock_movements (count_session_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_created_at ON stock_movements (created_at);
+
+CREATE TABLE IF NOT EXISTS count_sessions (
+    id SERIAL PRIMARY KEY,
+    location VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    note TEXT,
+    created_at TIMESTAMPTZ NULL,
+    closed_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_count_sessions_status ON count_sessions (status);
+
+CREATE TABLE IF NOT EXISTS count_lines (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    expected_quantity BIGINT NOT NULL,
+    counted_quantity BIGINT,
+    counted_at TIMESTAMPTZ NULL,
+    CONSTRAINT fk_count_sessions_lines FOREIGN KEY (session_id) REFERENCES count_sessions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_count_lines_product FOREIGN KEY (product_id) REFERENCES products(id)
+);
+CREATE INDEX IF NOT EXISTS idx_count_lines_session_id ON count_lines (session_id);
+
+CREATE TABLE IF NOT EXISTS adjustments (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    bin_id INTEGER,
+    action VARCHAR(10) NOT NULL,
+    quantity BIGINT NOT NULL,
+    unit_cost NUMERIC(12, 4),
+    value NUMERIC(12, 2) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    note TEXT,
+    status VARCHAR(20) NOT NULL,
+    decision_note TEXT,
+    created_at TIMESTAMPTZ NULL,
+    decided_at TIMESTAMPTZ NULL,
+    CONSTRAINT fk_adjustments_product FOREIGN KEY (product_id) REFERENCES products(id)
+);
+CREATE INDEX IF NOT EXISTS idx_adjustments_status ON adjustments (status);
+
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    movement_id INTEGER,
+    quantity BIGINT NOT NULL,
+    remaining BIGINT NOT NULL,
+    unit_cost NUMERIC(12, 4) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_cost_layers_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_cost_layers_product_received ON cost_layers (product_id, received_at);
+
+CREATE TABLE IF NOT EXISTS report_schedules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    report VARCHAR(50) NOT NULL,
+    params TEXT,
+    cron VARCHAR(100) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    sink VARCHAR(20) NOT NULL,
+    target VARCHAR(500),
+    active BOOLEAN NOT NULL,
+    next_run_at TIMESTAMPTZ NULL,
+    last_run_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NULL,
+    updated_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_report_schedules_next_run_at ON report_schedules (next_run_at);
+
+CREATE TABLE IF NOT EXISTS report_deliveries (
+    id SERIAL PRIMARY KEY,
+    schedule_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    sink VARCHAR(20) NOT NULL,
+    destination VARCHAR(500),
+    file_name VARCHAR(255),
+    size BIGINT,
+    "rows" BIGINT,
+    error TEXT,
+    started_at TIMESTAMPTZ NULL,
+    finished_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_schedule_id ON report_deliveries (schedule_id);
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_started_at ON report_deliveries (started_at);
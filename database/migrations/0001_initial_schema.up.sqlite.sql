@@ -0,0 +1,165 @@
+-- Initial schema: products, stock, orders, bins, the movement ledger, cycle
+-- counts, adjustments, cost layers and report schedules
+
+CREATE TABLE IF NOT EXISTS products (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    category VARCHAR(50) NOT NULL,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    image_path VARCHAR(255),
+    standard_cost DECIMAL(12, 4) NOT NULL DEFAULT 0,
+    average_cost DECIMAL(12, 4) NOT NULL DEFAULT 0,
+    abc_class VARCHAR(1),
+    xyz_class VARCHAR(1),
+    classified_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_products_abc_class ON products (abc_class);
+CREATE INDEX IF NOT EXISTS idx_products_xyz_class ON products (xyz_class);
+
+CREATE TABLE IF NOT EXISTS inventories (
+    product_id INTEGER NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, location),
+    CONSTRAINT fk_inventories_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    order_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    location VARCHAR(100) NOT NULL DEFAULT 'Warehouse A',
+    order_date DATETIME NOT NULL,
+    total_price DECIMAL(10, 2) NOT NULL,
+    cost_of_goods DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    CONSTRAINT fk_orders_product FOREIGN KEY (product_id) REFERENCES products(id)
+);
+CREATE INDEX IF NOT EXISTS idx_orders_date ON orders (order_date);
+
+CREATE TABLE IF NOT EXISTS bins (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    location VARCHAR(100) NOT NULL,
+    zone VARCHAR(20),
+    aisle VARCHAR(20),
+    shelf VARCHAR(20),
+    code VARCHAR(50) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bins_location_code ON bins (location, code);
+
+CREATE TABLE IF NOT EXISTS bin_stocks (
+    product_id INTEGER NOT NULL,
+    bin_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, bin_id),
+    CONSTRAINT fk_bin_stocks_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_bin_stocks_bin FOREIGN KEY (bin_id) REFERENCES bins(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    bin_id INTEGER,
+    quantity INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    reason VARCHAR(20),
+    reference VARCHAR(100),
+    count_session_id INTEGER,
+    unit_cost DECIMAL(12, 4) NOT NULL DEFAULT 0,
+    cost_value DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    created_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_movements_product_location ON stock_movements (product_id, location);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_count_session_id ON stock_movements (count_session_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_created_at ON stock_movements (created_at);
+
+CREATE TABLE IF NOT EXISTS count_sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    location VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    note TEXT,
+    created_at DATETIME NULL,
+    closed_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_count_sessions_status ON count_sessions (status);
+
+CREATE TABLE IF NOT EXISTS count_lines (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    expected_quantity INTEGER NOT NULL,
+    counted_quantity INTEGER,
+    counted_at DATETIME NULL,
+    CONSTRAINT fk_count_sessions_lines FOREIGN KEY (session_id) REFERENCES count_sessions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_count_lines_product FOREIGN KEY (product_id) REFERENCES products(id)
+);
+CREATE INDEX IF NOT EXISTS idx_count_lines_session_id ON count_lines (session_id);
+
+CREATE TABLE IF NOT EXISTS adjustments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    bin_id INTEGER,
+    action VARCHAR(10) NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit_cost DECIMAL(12, 4),
+    value DECIMAL(12, 2) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    note TEXT,
+    status VARCHAR(20) NOT NULL,
+    decision_note TEXT,
+    created_at DATETIME NULL,
+    decided_at DATETIME NULL,
+    CONSTRAINT fk_adjustments_product FOREIGN KEY (product_id) REFERENCES products(id)
+);
+CREATE INDEX IF NOT EXISTS idx_adjustments_status ON adjustments (status);
+
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    movement_id INTEGER,
+    quantity INTEGER NOT NULL,
+    remaining INTEGER NOT NULL,
+    unit_cost DECIMAL(12, 4) NOT NULL,
+    received_at DATETIME NOT NULL,
+    CONSTRAINT fk_cost_layers_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_cost_layers_product_received ON cost_layers (product_id, received_at);
+
+CREATE TABLE IF NOT EXISTS report_schedules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    report VARCHAR(50) NOT NULL,
+    params TEXT,
+    cron VARCHAR(100) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    sink VARCHAR(20) NOT NULL,
+    target VARCHAR(500),
+    active BOOLEAN NOT NULL,
+    next_run_at DATETIME NULL,
+    last_run_at DATETIME NULL,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_report_schedules_next_run_at ON report_schedules (next_run_at);
+
+CREATE TABLE IF NOT EXISTS report_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    schedule_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    sink VARCHAR(20) NOT NULL,
+    destination VARCHAR(500),
+    file_name VARCHAR(255),
+    size INTEGER,
+    "rows" INTEGER,
+    error TEXT,
+    started_at DATETIME NULL,
+    finished_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_schedule_id ON report_deliveries (schedule_id);
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_started_at ON report_deliveries (started_at);
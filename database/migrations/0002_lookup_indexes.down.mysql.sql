@@ -0,0 +1,2 @@
+DROP INDEX idx_inventories_location ON inventories;
+DROP INDEX idx_products_category ON products;
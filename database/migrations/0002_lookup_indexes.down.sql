@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_inventories_location;
+DROP INDEX IF EXISTS idx_products_category;
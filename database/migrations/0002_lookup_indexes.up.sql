@@ -0,0 +1,3 @@
+-- Indexes for filtering products by category and stock by location
+CREATE INDEX idx_products_category ON products (category);
+CREATE INDEX idx_inventories_location ON inventories (location);
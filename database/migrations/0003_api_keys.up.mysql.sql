@@ -0,0 +1,10 @@
+-- API keys for programmatic clients; only a hash of each key is stored
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    created_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_api_keys_key_hash (key_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,11 @@
+-- Users who sign in to the API, with bcrypt password hashes
+
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN locations;
+ALTER TABLE users DROP COLUMN role;
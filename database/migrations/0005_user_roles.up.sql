@@ -0,0 +1,4 @@
+-- Roles and location scopes for users; existing users keep full access
+
+ALTER TABLE users ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'admin';
+ALTER TABLE users ADD COLUMN locations TEXT;
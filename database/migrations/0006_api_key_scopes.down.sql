@@ -0,0 +1,5 @@
+ALTER TABLE api_keys DROP COLUMN revoked_at;
+ALTER TABLE api_keys DROP COLUMN last_used_at;
+ALTER TABLE api_keys DROP COLUMN expires_at;
+ALTER TABLE api_keys DROP COLUMN locations;
+ALTER TABLE api_keys DROP COLUMN scopes;
@@ -0,0 +1,8 @@
+-- Scopes, location limits, expiry, last use and revocation for API keys. Keys
+-- issued before scopes existed have none and are refused every permission.
+
+ALTER TABLE api_keys ADD COLUMN scopes TEXT;
+ALTER TABLE api_keys ADD COLUMN locations TEXT;
+ALTER TABLE api_keys ADD COLUMN expires_at DATETIME(3) NULL;
+ALTER TABLE api_keys ADD COLUMN last_used_at DATETIME(3) NULL;
+ALTER TABLE api_keys ADD COLUMN revoked_at DATETIME(3) NULL;
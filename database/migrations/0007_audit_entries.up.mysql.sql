@@ -0,0 +1,16 @@
+-- Audit trail of writes to products, inventory levels and orders
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    actor VARCHAR(150) NOT NULL,
+    request_id VARCHAR(64),
+    entity VARCHAR(20) NOT NULL,
+    entity_id VARCHAR(150) NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    changes TEXT,
+    created_at DATETIME(3) NULL,
+    INDEX idx_audit_entries_actor (actor),
+    INDEX idx_audit_entries_request_id (request_id),
+    INDEX idx_audit_entries_entity (entity, entity_id),
+    INDEX idx_audit_entries_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
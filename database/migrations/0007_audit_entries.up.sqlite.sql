@@ -0,0 +1,16 @@
+-- Audit trail of writes to products, inventory levels and orders
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor VARCHAR(150) NOT NULL,
+    request_id VARCHAR(64),
+    entity VARCHAR(20) NOT NULL,
+    entity_id VARCHAR(150) NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    changes TEXT,
+    created_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_actor ON audit_entries (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_request_id ON audit_entries (request_id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_entity ON audit_entries (entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries (created_at);
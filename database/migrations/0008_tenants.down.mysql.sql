@@ -0,0 +1,19 @@
+-- Fails while two tenants have a bin with the same code at the same location
+
+ALTER TABLE bins DROP INDEX idx_bins_location_code, ADD UNIQUE INDEX idx_bins_location_code (location, code);
+ALTER TABLE audit_entries DROP INDEX idx_audit_entries_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE users DROP INDEX idx_users_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE api_keys DROP INDEX idx_api_keys_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE report_deliveries DROP INDEX idx_report_deliveries_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE report_schedules DROP INDEX idx_report_schedules_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE cost_layers DROP INDEX idx_cost_layers_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE adjustments DROP INDEX idx_adjustments_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE count_lines DROP INDEX idx_count_lines_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE count_sessions DROP INDEX idx_count_sessions_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE stock_movements DROP INDEX idx_stock_movements_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE bin_stocks DROP INDEX idx_bin_stocks_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE bins DROP INDEX idx_bins_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE orders DROP INDEX idx_orders_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE inventories DROP INDEX idx_inventories_tenant_id, DROP COLUMN tenant_id;
+ALTER TABLE products DROP INDEX idx_products_tenant_id, DROP COLUMN tenant_id;
+DROP TABLE IF EXISTS tenants;
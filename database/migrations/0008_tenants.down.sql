@@ -0,0 +1,35 @@
+-- Fails while two tenants have a bin with the same code at the same location
+
+DROP INDEX IF EXISTS idx_bins_location_code;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bins_location_code ON bins (location, code);
+DROP INDEX IF EXISTS idx_audit_entries_tenant_id;
+ALTER TABLE audit_entries DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_users_tenant_id;
+ALTER TABLE users DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_api_keys_tenant_id;
+ALTER TABLE api_keys DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_report_deliveries_tenant_id;
+ALTER TABLE report_deliveries DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_report_schedules_tenant_id;
+ALTER TABLE report_schedules DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_cost_layers_tenant_id;
+ALTER TABLE cost_layers DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_adjustments_tenant_id;
+ALTER TABLE adjustments DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_count_lines_tenant_id;
+ALTER TABLE count_lines DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_count_sessions_tenant_id;
+ALTER TABLE count_sessions DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_stock_movements_tenant_id;
+ALTER TABLE stock_movements DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_bin_stocks_tenant_id;
+ALTER TABLE bin_stocks DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_bins_tenant_id;
+ALTER TABLE bins DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_orders_tenant_id;
+ALTER TABLE orders DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_inventories_tenant_id;
+ALTER TABLE inventories DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_products_tenant_id;
+ALTER TABLE products DROP COLUMN tenant_id;
+DROP TABLE IF EXISTS tenants;
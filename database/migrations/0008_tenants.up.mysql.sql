@@ -0,0 +1,34 @@
+-- Tenants share one deployment; every row belongs to one. Existing data is
+-- given to the default tenant, which keeps the original categories.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    categories TEXT,
+    locations TEXT,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_tenants_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO tenants (name, categories, created_at, updated_at)
+VALUES ('default', '["Electronics","Apparel","Footwear","Furniture","Appliances"]', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+ALTER TABLE products ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_products_tenant_id (tenant_id);
+ALTER TABLE inventories ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_inventories_tenant_id (tenant_id);
+ALTER TABLE orders ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_orders_tenant_id (tenant_id);
+ALTER TABLE bins ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_bins_tenant_id (tenant_id);
+ALTER TABLE bin_stocks ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_bin_stocks_tenant_id (tenant_id);
+ALTER TABLE stock_movements ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_stock_movements_tenant_id (tenant_id);
+ALTER TABLE count_sessions ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_count_sessions_tenant_id (tenant_id);
+ALTER TABLE count_lines ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_count_lines_tenant_id (tenant_id);
+ALTER TABLE adjustments ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_adjustments_tenant_id (tenant_id);
+ALTER TABLE cost_layers ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_cost_layers_tenant_id (tenant_id);
+ALTER TABLE report_schedules ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_report_schedules_tenant_id (tenant_id);
+ALTER TABLE report_deliveries ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_report_deliveries_tenant_id (tenant_id);
+ALTER TABLE api_keys ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_api_keys_tenant_id (tenant_id);
+ALTER TABLE users ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_users_tenant_id (tenant_id);
+ALTER TABLE audit_entries ADD COLUMN tenant_id INT UNSIGNED NOT NULL DEFAULT 1, ADD INDEX idx_audit_entries_tenant_id (tenant_id);
+
+-- Bin codes are unique within a tenant's location
+ALTER TABLE bins DROP INDEX idx_bins_location_code, ADD UNIQUE INDEX idx_bins_location_code (tenant_id, location, code);
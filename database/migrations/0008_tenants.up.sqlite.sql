@@ -0,0 +1,50 @@
+-- Tenants share one deployment; every row belongs to one. Existing data is
+-- given to the default tenant, which keeps the original categories.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    categories TEXT,
+    locations TEXT,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_name ON tenants (name);
+
+INSERT INTO tenants (name, categories, created_at, updated_at)
+VALUES ('default', '["Electronics","Apparel","Footwear","Furniture","Appliances"]', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+ALTER TABLE products ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_products_tenant_id ON products (tenant_id);
+ALTER TABLE inventories ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_inventories_tenant_id ON inventories (tenant_id);
+ALTER TABLE orders ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_orders_tenant_id ON orders (tenant_id);
+ALTER TABLE bins ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_bins_tenant_id ON bins (tenant_id);
+ALTER TABLE bin_stocks ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_bin_stocks_tenant_id ON bin_stocks (tenant_id);
+ALTER TABLE stock_movements ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_stock_movements_tenant_id ON stock_movements (tenant_id);
+ALTER TABLE count_sessions ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_count_sessions_tenant_id ON count_sessions (tenant_id);
+ALTER TABLE count_lines ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_count_lines_tenant_id ON count_lines (tenant_id);
+ALTER TABLE adjustments ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_adjustments_tenant_id ON adjustments (tenant_id);
+ALTER TABLE cost_layers ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_cost_layers_tenant_id ON cost_layers (tenant_id);
+ALTER TABLE report_schedules ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_report_schedules_tenant_id ON report_schedules (tenant_id);
+ALTER TABLE report_deliveries ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_report_deliveries_tenant_id ON report_deliveries (tenant_id);
+ALTER TABLE api_keys ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys (tenant_id);
+ALTER TABLE users ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+ALTER TABLE audit_entries ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_audit_entries_tenant_id ON audit_entries (tenant_id);
+
+-- Bin codes are unique within a tenant's location
+DROP INDEX IF EXISTS idx_bins_location_code;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bins_location_code ON bins (tenant_id, location, code);
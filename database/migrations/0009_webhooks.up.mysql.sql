@@ -0,0 +1,32 @@
+-- Webhook subscriptions and the log of every event delivered to them
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    tenant_id INT UNSIGNED NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    events TEXT,
+    secret VARCHAR(100) NOT NULL,
+    low_stock_threshold BIGINT NOT NULL DEFAULT 20,
+    active BOOLEAN NOT NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    INDEX idx_webhooks_tenant_id (tenant_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    tenant_id INT UNSIGNED NOT NULL,
+    webhook_id INT UNSIGNED NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    response_status BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    next_attempt_at DATETIME(3) NULL,
+    last_attempt_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    INDEX idx_webhook_deliveries_tenant_id (tenant_id),
+    INDEX idx_webhook_deliveries_webhook_id (webhook_id),
+    INDEX idx_webhook_deliveries_status (status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
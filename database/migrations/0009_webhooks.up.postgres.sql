@@ -0,0 +1,32 @@
+-- Webhook subscriptions and the log of every event delivered to them
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    events TEXT,
+    secret VARCHAR(100) NOT NULL,
+    low_stock_threshold BIGINT NOT NULL DEFAULT 20,
+    active BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NULL,
+    updated_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_tenant_id ON webhooks (tenant_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    webhook_id INTEGER NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    response_status BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    next_attempt_at TIMESTAMPTZ NULL,
+    last_attempt_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_tenant_id ON webhook_deliveries (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status, next_attempt_at);
@@ -0,0 +1,2 @@
+ALTER TABLE webhook_deliveries DROP INDEX idx_webhook_deliveries_event_id, DROP COLUMN event_id;
+DROP TABLE IF EXISTS outbox_events;
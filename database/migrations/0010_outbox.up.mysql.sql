@@ -0,0 +1,20 @@
+-- Events recorded in the transaction of the change they describe, until the
+-- relay publishes them; webhook deliveries remember the event they came from
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    tenant_id INT UNSIGNED NOT NULL,
+    aggregate_type VARCHAR(20) NOT NULL,
+    aggregate_id VARCHAR(150) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    next_attempt_at DATETIME(3) NULL,
+    published_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    INDEX idx_outbox_events_tenant_id (tenant_id),
+    INDEX idx_outbox_events_published_at (published_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE webhook_deliveries ADD COLUMN event_id INT UNSIGNED NULL, ADD INDEX idx_webhook_deliveries_event_id (event_id);
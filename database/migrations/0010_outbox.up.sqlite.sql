@@ -0,0 +1,21 @@
+-- Events recorded in the transaction of the change they describe, until the
+-- relay publishes them; webhook deliveries remember the event they came from
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL,
+    aggregate_type VARCHAR(20) NOT NULL,
+    aggregate_id VARCHAR(150) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    next_attempt_at DATETIME NULL,
+    published_at DATETIME NULL,
+    created_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_tenant_id ON outbox_events (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events (published_at);
+
+ALTER TABLE webhook_deliveries ADD COLUMN event_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries (event_id);
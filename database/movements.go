@@ -0,0 +1,67 @@
+// database/movements.go
+package database
+
+import (
+	"inventory_system/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecordMovement writes a stock movement to the ledger and values it under the
+// costing method. Movements of a product and location that are part of an open
+// count session are flagged with that session.
+func RecordMovement(tx *gorm.DB, movement *models.StockMovement) error {
+	if movement.CountSessionID == nil && movement.Type != models.MovementCount {
+		var line models.CountLine
+		result := tx.Select("count_lines.*").
+			Joins("JOIN count_sessions ON count_lines.session_id = count_sessions.id").
+			Where("count_sessions.status = ? AND count_lines.product_id = ? AND count_lines.location = ?",
+				models.CountOpen, movement.ProductID, movement.Location).
+			Limit(1).
+			Find(&line)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			movement.CountSessionID = &line.SessionID
+		}
+	}
+
+	if movement.CreatedAt.IsZero() {
+		movement.CreatedAt = time.Now()
+	}
+	if err := tx.Create(movement).Error; err != nil {
+		return err
+	}
+
+	// Value the movement once it has an ID that cost layers can refer to
+	if err := applyMovementCost(tx, movement); err != nil {
+		return err
+	}
+	if movement.UnitCost == 0 && movement.CostValue == 0 {
+		return nil
+	}
+	return tx.Model(movement).Updates(map[string]interface{}{
+		"unit_cost":  movement.UnitCost,
+		"cost_value": movement.CostValue,
+	}).Error
+}
+
+// GetMovements returns stock movements filtered by product, location and count session
+func GetMovements(db *gorm.DB, productID uint, location string, countSessionID uint) ([]models.StockMovement, error) {
+	var movements []models.StockMovement
+
+	if productID != 0 {
+		db = db.Where("product_id = ?", productID)
+	}
+	if location != "" {
+		db = db.Where("location = ?", location)
+	}
+	if countSessionID != 0 {
+		db = db.Where("count_session_id = ?", countSessionID)
+	}
+
+	err := db.Order("created_at DESC, id DESC").Find(&movements).Error
+	return movements, err
+}
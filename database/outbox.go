@@ -0,0 +1,51 @@
+// database/outbox.go
+package database
+
+import (
+	"encoding/json"
+	"inventory_system/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryChange is the data of inventory events. Threshold is only set for
+// inventory.low_stock, to the threshold of the webhook it is sent to.
+type InventoryChange struct {
+	ProductID        uint   `json:"product_id"`
+	Location         string `json:"location"`
+	Quantity         int    `json:"quantity"`
+	PreviousQuantity int    `json:"previous_quantity"`
+	Threshold        int    `json:"threshold,omitempty"`
+}
+
+// RecordEvent writes an event about an aggregate to the outbox in the same
+// transaction as the change it describes, so that an event is published if,
+// and only if, the change is committed
+func RecordEvent(tx *gorm.DB, aggregateType, aggregateID, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Event:         event,
+		Payload:       string(payload),
+		CreatedAt:     time.Now(),
+	}).Error
+}
+
+// recordInventoryChange records inventory.changed when the stock of a product
+// at a location changes
+func recordInventoryChange(tx *gorm.DB, entityID string, previous int, inventory models.Inventory) error {
+	if inventory.Quantity == previous {
+		return nil
+	}
+	return RecordEvent(tx, models.EntityInventory, entityID, models.EventInventoryChanged, InventoryChange{
+		ProductID:        inventory.ProductID,
+		Location:         inventory.Location,
+		Quantity:         inventory.Quantity,
+		PreviousQuantity: previous,
+	})
+}
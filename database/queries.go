@@ -2,85 +2,164 @@
 package database
 
 import (
-
 	"gorm.io/gorm"
-	"log"
+	"time"
 )
 
+// LowStockRow is a product whose stock at a location is below the threshold
+type LowStockRow struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Location string `json:"location"`
+	Quantity int    `json:"quantity"`
+}
+
+// CategoryRevenueRow holds the revenue of one product category
+type CategoryRevenueRow struct {
+	Category     string  `json:"category"`
+	TotalRevenue float64 `json:"total_revenue"`
+	OrderCount   int     `json:"order_count"`
+}
+
+// LocationStockRow holds the total stock held at one location
+type LocationStockRow struct {
+	Location     string `json:"location"`
+	TotalStock   int    `json:"total_stock"`
+	ProductCount int    `json:"product_count"`
+	BinCount     int    `json:"bin_count,omitempty"`
+}
+
+// TopSellerRow holds the units sold and revenue of one product
+type TopSellerRow struct {
+	ID           uint    `json:"id"`
+	Name         string  `json:"name"`
+	TotalSold    int     `json:"total_sold"`
+	TotalRevenue float64 `json:"total_revenue"`
+}
+
+// CategoryValueRow holds the stock quantity and value of one product category
+type CategoryValueRow struct {
+	Category      string  `json:"category"`
+	TotalQuantity int     `json:"total_quantity"`
+	TotalValue    float64 `json:"total_value"`
+}
+
+// ProductValueRow holds the stock quantity and value of one product
+type ProductValueRow struct {
+	ID         uint    `json:"id"`
+	Name       string  `json:"name"`
+	Category   string  `json:"category"`
+	Quantity   int     `json:"quantity"`
+	TotalValue float64 `json:"total_value"`
+}
+
 // GetLowStockProducts returns products with quantity less than threshold
-func GetLowStockProducts(db *gorm.DB, threshold int) ([]map[string]interface{}, error) {
-	var results []map[string]interface{}
-	
+func GetLowStockProducts(db *gorm.DB, threshold int) ([]LowStockRow, error) {
+	results := []LowStockRow{}
+
 	// Using efficient JOIN with INDEX hints for MySQL
 	err := db.Table("inventories").
 		Select("products.id, products.name, products.category, inventories.location, inventories.quantity").
-		Joins("JOIN products USE INDEX (PRIMARY) ON inventories.product_id = products.id").
+		Joins("JOIN products" + primaryKeyHint(db) + " ON inventories.product_id = products.id").
 		Where("inventories.quantity < ?", threshold).
-		Find(&results).Error
-	
+		Order("inventories.quantity, products.id").
+		Scan(&results).Error
+
 	return results, err
 }
 
-// GetRevenueByCategory calculates total revenue per product category
-func GetRevenueByCategory(db *gorm.DB) ([]map[string]interface{}, error) {
-	var results []map[string]interface{}
-	
-	// Using JOIN with INDEX hints and efficient aggregation
-	err := db.Table("orders").
-        Select("products.category, SUM(orders.total_price) as total_revenue, COUNT(orders.order_id) as order_count").
-        // Joins("JOIN products USE INDEX (PRIMARY) ON orders.product_id = products.id"). // Potential issue
-        Joins("JOIN products ON orders.product_id = products.id"). // Use standard join first
-        Group("products.category").
-        Find(&results).Error
-
-    // --- TEMPORARY LOGGING ---
-    if err != nil {
-        log.Printf("DEBUG: GetRevenueByCategory DB Error: %v\n", err)
-    } else {
-        log.Printf("DEBUG: GetRevenueByCategory Success. Rows found: %d\n", len(results))
-    }
-    // --- END TEMPORARY LOGGING ---
-
-    return results, err
+// GetRevenueByCategory calculates total revenue per product category,
+// optionally restricted to orders placed in [from, to)
+func GetRevenueByCategory(db *gorm.DB, from, to *time.Time) ([]CategoryRevenueRow, error) {
+	results := []CategoryRevenueRow{}
+
+	query := db.Table("orders").
+		Select("products.category, SUM(orders.total_price) as total_revenue, COUNT(orders.order_id) as order_count").
+		Joins("JOIN products ON orders.product_id = products.id")
+	query = orderDateRange(query, from, to)
+
+	err := query.Group("products.category").
+		Order("products.category").
+		Scan(&results).Error
+
+	return results, err
 }
 
-// GetStockDistributionByLocation shows total stock across all locations
-func GetStockDistributionByLocation(db *gorm.DB) ([]map[string]interface{}, error) {
-	var results []map[string]interface{}
-	
-	// Using index on location for faster grouping
-	err := db.Table("inventories").
-		Select("location, SUM(quantity) as total_stock, COUNT(DISTINCT product_id) as product_count").
-		Group("location").
-		Find(&results).Error
-	
+// GetStockDistributionByLocation shows total stock across all locations, either
+// now or, when asOf is set, rebuilt from the movement ledger at that point in time.
+// Historical results carry no bin count because not every movement names a bin.
+func GetStockDistributionByLocation(db *gorm.DB, asOf *time.Time) ([]LocationStockRow, error) {
+	results := []LocationStockRow{}
+
+	if asOf != nil {
+		err := db.Table("(?) as balances", balancesAsOf(db, *asOf)).
+			Select("location, SUM(quantity) as total_stock, COUNT(DISTINCT product_id) as product_count").
+			Group("location").
+			Order("location").
+			Scan(&results).Error
+
+		return results, err
+	}
+
+	// Stock is held in bins, so location totals are the sum of their bins
+	err := db.Table("bin_stocks").
+		Select("bins.location, SUM(bin_stocks.quantity) as total_stock, COUNT(DISTINCT bin_stocks.product_id) as product_count, COUNT(DISTINCT bins.id) as bin_count").
+		Joins("JOIN bins ON bin_stocks.bin_id = bins.id").
+		Group("bins.location").
+		Order("bins.location").
+		Scan(&results).Error
+
 	return results, err
 }
 
-// GetTopSellingProducts returns the top selling products
-func GetTopSellingProducts(db *gorm.DB, limit int) ([]map[string]interface{}, error) {
-	var results []map[string]interface{}
-	
-	err := db.Table("orders").
+// GetTopSellingProducts returns the top selling products, optionally restricted
+// to orders placed in [from, to)
+func GetTopSellingProducts(db *gorm.DB, limit int, from, to *time.Time) ([]TopSellerRow, error) {
+	results := []TopSellerRow{}
+
+	query := db.Table("orders").
 		Select("products.id, products.name, SUM(orders.quantity) as total_sold, SUM(orders.total_price) as total_revenue").
-		Joins("JOIN products ON orders.product_id = products.id").
-		Group("products.id").
+		Joins("JOIN products ON orders.product_id = products.id")
+	query = orderDateRange(query, from, to)
+
+	err := query.Group("products.id, products.name").
 		Order("total_sold DESC").
 		Limit(limit).
-		Find(&results).Error
-	
+		Scan(&results).Error
+
 	return results, err
 }
 
 // GetInventoryValueByCategory calculates the total inventory value per category
-func GetInventoryValueByCategory(db *gorm.DB) ([]map[string]interface{}, error) {
-	var results []map[string]interface{}
-	
-	err := db.Table("inventories").
-		Select("products.category, SUM(inventories.quantity * products.price) as total_value").
-		Joins("JOIN products ON inventories.product_id = products.id").
+// at cost as of a point in time, under the costing method used when the stock moved
+func GetInventoryValueByCategory(db *gorm.DB, asOf time.Time) ([]CategoryValueRow, error) {
+	results := []CategoryValueRow{}
+
+	err := db.Table("stock_movements").
+		Select("products.category, SUM(stock_movements.quantity) as total_quantity, SUM(stock_movements.cost_value) as total_value").
+		Joins("JOIN products ON stock_movements.product_id = products.id").
+		Where("stock_movements.created_at <= ?", asOf).
 		Group("products.category").
-		Find(&results).Error
-	
+		Order("products.category").
+		Scan(&results).Error
+
 	return results, err
-}
\ No newline at end of file
+}
+
+// GetInventoryValuation values the stock of every product as of a point in time
+// by summing the valued movements up to that moment
+func GetInventoryValuation(db *gorm.DB, asOf time.Time) ([]ProductValueRow, error) {
+	results := []ProductValueRow{}
+
+	err := db.Table("stock_movements").
+		Select("products.id, products.name, products.category, SUM(stock_movements.quantity) as quantity, SUM(stock_movements.cost_value) as total_value").
+		Joins("JOIN products ON stock_movements.product_id = products.id").
+		Where("stock_movements.created_at <= ?", asOf).
+		Group("products.id, products.name, products.category").
+		Having("SUM(stock_movements.quantity) <> 0").
+		Order("products.id").
+		Scan(&results).Error
+
+	return results, err
+}
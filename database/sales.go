@@ -0,0 +1,216 @@
+// database/sales.go
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supported time-series granularities
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// MaxSalesBuckets caps the number of time buckets a single sales report can return
+const MaxSalesBuckets = 1000
+
+// SalesPoint holds the sales of one group in one time bucket
+type SalesPoint struct {
+	Bucket  string  `json:"bucket"`
+	Start   string  `json:"start"`
+	Orders  int     `json:"orders"`
+	Units   int     `json:"units"`
+	Revenue float64 `json:"revenue"`
+}
+
+// SalesSeries holds the zero-filled sales of one group over the report range
+type SalesSeries struct {
+	Group string `json:"group"`
+	// ProductID is set when grouping by product, since product names need not be unique
+	ProductID    uint         `json:"product_id,omitempty"`
+	TotalOrders  int          `json:"total_orders"`
+	TotalUnits   int          `json:"total_units"`
+	TotalRevenue float64      `json:"total_revenue"`
+	Points       []SalesPoint `json:"points"`
+}
+
+// SalesReport is a time series of sales ready for charting
+type SalesReport struct {
+	From        string        `json:"from"`
+	To          string        `json:"to"`
+	Granularity string        `json:"granularity"`
+	GroupBy     string        `json:"group_by,omitempty"`
+	TimeZone    string        `json:"time_zone"`
+	Buckets     []string      `json:"buckets"`
+	Series      []SalesSeries `json:"series"`
+	Totals      SalesSeries   `json:"totals"`
+}
+
+// salesGroups maps a group-by option to the expression orders are grouped on
+var salesGroups = map[string]string{
+	"":         "''",
+	"category": "products.category",
+	"product":  "products.name",
+	"location": "orders.location",
+}
+
+// IsValidSalesGroup reports whether sales can be grouped by the given option
+func IsValidSalesGroup(groupBy string) bool {
+	_, ok := salesGroups[groupBy]
+	return ok
+}
+
+// IsValidGranularity reports whether the given time-series granularity is supported
+func IsValidGranularity(granularity string) bool {
+	return granularity == GranularityDay || granularity == GranularityWeek || granularity == GranularityMonth
+}
+
+// BucketStart returns the start of the day, ISO week or month containing t in loc
+func BucketStart(t time.Time, granularity string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch granularity {
+	case GranularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// NextBucket returns the start of the bucket following the one starting at start
+func NextBucket(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// GetSalesSeries buckets the orders placed in [from, to) by day, week or month in
+// the reporting time zone, optionally split by category, product or location.
+// Every series has a point for every bucket, with zeros where nothing was sold.
+func GetSalesSeries(db *gorm.DB, from, to time.Time, granularity, groupBy string, loc *time.Location) (SalesReport, error) {
+	report := SalesReport{
+		From:        from.In(loc).Format(time.RFC3339),
+		To:          to.In(loc).Format(time.RFC3339),
+		Granularity: granularity,
+		GroupBy:     groupBy,
+		TimeZone:    loc.String(),
+		Buckets:     []string{},
+		Series:      []SalesSeries{},
+	}
+
+	expression, ok := salesGroups[groupBy]
+	if !ok {
+		return report, fmt.Errorf("unknown sales group %q", groupBy)
+	}
+
+	var starts []time.Time
+	index := make(map[int64]int)
+	for start := BucketStart(from, granularity, loc); start.Before(to); start = NextBucket(start, granularity) {
+		if len(starts) == MaxSalesBuckets {
+			return report, fmt.Errorf("date range exceeds %d %s buckets", MaxSalesBuckets, granularity)
+		}
+		index[start.Unix()] = len(starts)
+		starts = append(starts, start)
+		report.Buckets = append(report.Buckets, start.Format("2006-01-02"))
+	}
+
+	rows, err := db.Table("orders").
+		Select(expression+" as group_key, orders.product_id, orders.order_date, orders.quantity, orders.total_price").
+		Joins("JOIN products ON orders.product_id = products.id").
+		Where("orders.order_date >= ? AND orders.order_date < ?", from, to).
+		Rows()
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	newSeries := func(group string) *SalesSeries {
+		series := &SalesSeries{Group: group, Points: make([]SalesPoint, len(starts))}
+		for i, start := range starts {
+			series.Points[i] = SalesPoint{Bucket: report.Buckets[i], Start: start.Format(time.RFC3339)}
+		}
+		return series
+	}
+
+	// Products are told apart by ID, since two may share a name
+	type seriesKey struct {
+		group     string
+		productID uint
+	}
+
+	totals := newSeries("all")
+	groups := make(map[seriesKey]*SalesSeries)
+	var keys []seriesKey
+	for rows.Next() {
+		var group string
+		var productID uint
+		var orderDate time.Time
+		var quantity int
+		var totalPrice float64
+		if err := rows.Scan(&group, &productID, &orderDate, &quantity, &totalPrice); err != nil {
+			return report, err
+		}
+
+		i, ok := index[BucketStart(orderDate, granularity, loc).Unix()]
+		if !ok {
+			continue
+		}
+
+		series := totals
+		if groupBy != "" {
+			key := seriesKey{group: group}
+			if groupBy == "product" {
+				key.productID = productID
+			}
+			if series, ok = groups[key]; !ok {
+				series = newSeries(group)
+				series.ProductID = key.productID
+				groups[key] = series
+				keys = append(keys, key)
+			}
+			addSale(totals, i, quantity, totalPrice)
+		}
+		addSale(series, i, quantity, totalPrice)
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].productID < keys[j].productID
+	})
+	for _, key := range keys {
+		report.Series = append(report.Series, *groups[key])
+	}
+	if groupBy == "" {
+		report.Series = append(report.Series, *totals)
+	}
+	report.Totals = *totals
+
+	return report, nil
+}
+
+// addSale adds one order to a bucket of a series
+func addSale(series *SalesSeries, bucket, quantity int, revenue float64) {
+	series.Points[bucket].Orders++
+	series.Points[bucket].Units += quantity
+	series.Points[bucket].Revenue += revenue
+	series.TotalOrders++
+	series.TotalUnits += quantity
+	series.TotalRevenue += revenue
+}
@@ -1,49 +0,0 @@
--- database/scripts/schema.sql
--- Run this script to set up the database from scratch
-
--- Create database if it doesn't exist
-CREATE DATABASE IF NOT EXISTS inventory;
-USE inventory;
-
--- Products table
-CREATE TABLE IF NOT EXISTS products (
-    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-    name VARCHAR(100) NOT NULL,
-    description TEXT,
-    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
-    category VARCHAR(50) NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    image_path VARCHAR(255)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
-
--- Create index on category for faster filtering
-CREATE INDEX idx_products_category ON products(category);
-
--- Inventory table
-CREATE TABLE IF NOT EXISTS inventories (
-    product_id INT UNSIGNED NOT NULL,
-    location VARCHAR(100) NOT NULL,
-    quantity INT NOT NULL DEFAULT 0,
-    PRIMARY KEY (product_id, location),
-    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
-
--- Create index on location for faster queries
-CREATE INDEX idx_inventories_location ON inventories(location);
-
--- Orders table
-CREATE TABLE IF NOT EXISTS orders (
-    order_id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
-    product_id INT UNSIGNED NOT NULL,
-    quantity INT NOT NULL CHECK (quantity > 0),
-    order_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    total_price DECIMAL(10, 2) NOT NULL,
-    FOREIGN KEY (product_id) REFERENCES products(id)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
-
--- Create index on order_date for faster date range queries
-CREATE INDEX idx_orders_date ON orders(order_date);
-
--- Sample data insertion
--- See seedData() function in Go code for implementation
\ No newline at end of file
@@ -0,0 +1,239 @@
+// database/tenants.go
+package database
+
+import (
+	"context"
+	"errors"
+	"inventory_system/models"
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTenantID is the tenant that owns the data created before tenants existed
+const DefaultTenantID uint = 1
+
+// ErrTenantRequired is returned when tenant data is read or written through a
+// context that names neither a tenant nor AllTenants
+var ErrTenantRequired = errors.New("tenant data accessed without a tenant")
+
+type (
+	tenantKey     struct{}
+	allTenantsKey struct{}
+)
+
+// WithTenant returns a context whose queries only see, and whose writes only
+// create, one tenant's data
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// AllTenants returns a context whose queries are not scoped to a tenant. It is
+// meant for finding credentials before the tenant is known and for jobs that
+// loop over tenants; records created through it must carry their TenantID.
+func AllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+// TenantFrom returns the tenant a context is scoped to
+func TenantFrom(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	tenantID, ok := ctx.Value(tenantKey{}).(uint)
+	return tenantID, ok && tenantID != 0
+}
+
+// tenantTables are the tables whose rows belong to a tenant
+var tenantTables = map[string]bool{
+	"products":           true,
+	"inventories":        true,
+	"orders":             true,
+	"bins":               true,
+	"bin_stocks":         true,
+	"stock_movements":    true,
+	"count_sessions":     true,
+	"count_lines":        true,
+	"adjustments":        true,
+	"cost_layers":        true,
+	"report_schedules":   true,
+	"report_deliveries":  true,
+	"api_keys":           true,
+	"users":              true,
+	"audit_entries":      true,
+	"webhooks":           true,
+	"webhook_deliveries": true,
+	"outbox_events":      true,
+}
+
+// RegisterTenantScope scopes every query, update and delete of tenant tables
+// to the tenant of the statement's context and stamps created rows with it.
+// Statements without a tenant fail with ErrTenantRequired unless their context
+// comes from AllTenants, so a forgotten WithContext cannot leak data.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:stamp", stampTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:scope", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:scope", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:scope", scopeTenant); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("tenant:scope", scopeTenant)
+}
+
+// statementTenant returns the tenant of a statement on a tenant table; scoped
+// is false for other tables and for AllTenants statements
+func statementTenant(db *gorm.DB) (tenantID uint, scoped bool) {
+	if !tenantTables[db.Statement.Table] {
+		return 0, false
+	}
+	ctx := db.Statement.Context
+	if tenantID, ok := TenantFrom(ctx); ok {
+		return tenantID, true
+	}
+	if ctx != nil {
+		if all, _ := ctx.Value(allTenantsKey{}).(bool); all {
+			return 0, false
+		}
+	}
+	db.AddError(ErrTenantRequired)
+	return 0, false
+}
+
+// scopeTenant limits a statement to the rows of its tenant and keeps saved
+// records in that tenant
+func scopeTenant(db *gorm.DB) {
+	tenantID, scoped := statementTenant(db)
+	if !scoped {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: "tenant_id"}, Value: tenantID},
+	}})
+	setTenant(db, tenantID)
+}
+
+// stampTenant gives created records the tenant of their context. Records
+// created for AllTenants must already carry one.
+func stampTenant(db *gorm.DB) {
+	if !tenantTables[db.Statement.Table] {
+		return
+	}
+	tenantID, scoped := statementTenant(db)
+	if scoped {
+		setTenant(db, tenantID)
+		return
+	}
+	if db.Error == nil && db.Statement.Schema != nil {
+		if field := db.Statement.Schema.LookUpField("TenantID"); field != nil {
+			eachRecord(db, func(record reflect.Value) {
+				if _, zero := field.ValueOf(db.Statement.Context, record); zero {
+					db.AddError(ErrTenantRequired)
+				}
+			})
+		}
+	}
+}
+
+// setTenant writes the tenant into the statement's records
+func setTenant(db *gorm.DB, tenantID uint) {
+	if db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("TenantID")
+	if field == nil {
+		return
+	}
+	eachRecord(db, func(record reflect.Value) {
+		db.AddError(field.Set(db.Statement.Context, record, tenantID))
+	})
+}
+
+// eachRecord calls fn with every struct the statement writes
+func eachRecord(db *gorm.DB, fn func(record reflect.Value)) {
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if record := reflect.Indirect(value.Index(i)); record.Kind() == reflect.Struct {
+				fn(record)
+			}
+		}
+	case reflect.Struct:
+		if value.CanAddr() {
+			fn(value)
+		}
+	}
+}
+
+// GetTenant returns the tenant a database handle's context is scoped to
+func GetTenant(db *gorm.DB) (models.Tenant, error) {
+	var tenant models.Tenant
+	tenantID, ok := TenantFrom(db.Statement.Context)
+	if !ok {
+		return tenant, ErrTenantRequired
+	}
+	err := db.First(&tenant, tenantID).Error
+	return tenant, err
+}
+
+// GetTenants returns every tenant, in the order they were created
+func GetTenants(db *gorm.DB) ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	err := db.Order("id").Find(&tenants).Error
+	return tenants, err
+}
+
+// ForEachTenant calls fn with a handle scoped to each tenant in turn, stopping
+// at the first error
+func ForEachTenant(db *gorm.DB, fn func(tenant models.Tenant, tx *gorm.DB) error) error {
+	tenants, err := GetTenants(db)
+	if err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		if err := fn(tenant, db.WithContext(WithTenant(db.Statement.Context, tenant.ID))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindTenant returns the tenant with a name or ID; an empty reference finds the
+// default tenant
+func FindTenant(db *gorm.DB, reference string) (models.Tenant, error) {
+	var tenant models.Tenant
+	if reference == "" {
+		err := db.First(&tenant, DefaultTenantID).Error
+		return tenant, err
+	}
+	if id, err := strconv.ParseUint(reference, 10, 32); err == nil {
+		err := db.First(&tenant, uint(id)).Error
+		return tenant, err
+	}
+	err := db.Where("name = ?", reference).First(&tenant).Error
+	return tenant, err
+}
+
+// HasLocation reports whether a tenant keeps stock at a location; tenants that
+// have not listed their locations accept any
+func HasLocation(tenant models.Tenant, location string) bool {
+	if len(tenant.Locations) == 0 {
+		return true
+	}
+	for _, known := range tenant.Locations {
+		if known == location {
+			return true
+		}
+	}
+	return false
+}
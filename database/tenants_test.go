@@ -0,0 +1,262 @@
+// database/tenants_test.go
+package database
+
+import (
+	"context"
+	"errors"
+	"inventory_system/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// tenantFixture is one record of each tenant table a tenant's fixture holds
+type tenantFixture struct {
+	product models.Product
+	order   models.Order
+	bin     models.Bin
+	stock   models.BinStock
+	webhook models.Webhook
+}
+
+// createTenantFixture creates the same records under a tenant's context; the
+// records share names and bin codes across tenants so only the tenant tells
+// them apart
+func createTenantFixture(t *testing.T, db *gorm.DB) tenantFixture {
+	t.Helper()
+
+	var f tenantFixture
+	f.product = models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25}
+	if err := db.Create(&f.product).Error; err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	f.order = models.Order{ProductID: f.product.ID, Quantity: 2, Location: "Store 1", OrderDate: time.Now(), TotalPrice: 50}
+	if err := db.Create(&f.order).Error; err != nil {
+		t.Fatalf("creating order: %v", err)
+	}
+	f.bin = models.Bin{Location: "Store 1", Code: "A-01"}
+	if err := db.Create(&f.bin).Error; err != nil {
+		t.Fatalf("creating bin: %v", err)
+	}
+	f.stock = models.BinStock{ProductID: f.product.ID, BinID: f.bin.ID, Quantity: 10}
+	if err := db.Create(&f.stock).Error; err != nil {
+		t.Fatalf("creating bin stock: %v", err)
+	}
+	f.webhook = models.Webhook{URL: "http://example.com/hook", Events: []string{models.EventOrderCreated}, Secret: "whsec_test", Active: true}
+	if err := db.Create(&f.webhook).Error; err != nil {
+		t.Fatalf("creating webhook: %v", err)
+	}
+	return f
+}
+
+// openTenantsDB returns handles scoped to the default tenant and to a second
+// tenant, an AllTenants handle and an unscoped one, with a fixture per tenant
+func openTenantsDB(t *testing.T) (first, second, all, unscoped *gorm.DB, mine, theirs tenantFixture) {
+	t.Helper()
+
+	first = openTestDB(t)
+	unscoped = first.WithContext(context.Background())
+	all = first.WithContext(AllTenants(context.Background()))
+
+	tenant := models.Tenant{Name: "acme", Categories: []string{"Furniture"}}
+	if err := unscoped.Create(&tenant).Error; err != nil {
+		t.Fatalf("creating tenant: %v", err)
+	}
+	second = first.WithContext(WithTenant(context.Background(), tenant.ID))
+
+	return first, second, all, unscoped, createTenantFixture(t, first), createTenantFixture(t, second)
+}
+
+// checkIsolation checks that a handle scoped to the default tenant neither sees
+// nor changes the other tenant's record of type T, found by theirs, while
+// reaching its own record, found by mine. It deletes the default tenant's
+// record at the end.
+func checkIsolation[T any](t *testing.T, db, all *gorm.DB, mine, theirs []interface{}, column string, value interface{}) {
+	t.Helper()
+
+	var rows []T
+	if err := db.Find(&rows).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("find returned %d rows, want only the tenant's own", len(rows))
+	}
+	if tenantID := reflect.ValueOf(rows[0]).FieldByName("TenantID").Uint(); tenantID != uint64(DefaultTenantID) {
+		t.Errorf("find returned a row of tenant %d", tenantID)
+	}
+
+	var row T
+	if err := db.Where(theirs[0], theirs[1:]...).First(&row).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("first of the other tenant's row returned %v, want record not found", err)
+	}
+	if err := db.Where(mine[0], mine[1:]...).First(&row).Error; err != nil {
+		t.Errorf("first of the tenant's own row: %v", err)
+	}
+
+	var count int64
+	if err := db.Model(new(T)).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("count returned %d (%v), want 1", count, err)
+	}
+
+	result := db.Model(new(T)).Where(theirs[0], theirs[1:]...).Update(column, value)
+	if result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("update of the other tenant's row changed %d rows (%v), want none", result.RowsAffected, result.Error)
+	}
+	result = db.Model(new(T)).Where(mine[0], mine[1:]...).Update(column, value)
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("update of the tenant's own row changed %d rows (%v), want 1", result.RowsAffected, result.Error)
+	}
+	if err := all.Model(new(T)).Where(theirs[0], theirs[1:]...).Where(column+" = ?", value).Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("the other tenant's row was updated (%v)", err)
+	}
+
+	result = db.Where(theirs[0], theirs[1:]...).Delete(new(T))
+	if result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("delete of the other tenant's row removed %d rows (%v), want none", result.RowsAffected, result.Error)
+	}
+	result = db.Where("1 = 1").Delete(new(T))
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("delete of every row removed %d rows (%v), want only the tenant's own", result.RowsAffected, result.Error)
+	}
+	if err := all.Model(new(T)).Where(theirs[0], theirs[1:]...).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("the other tenant's row is gone (%v)", err)
+	}
+}
+
+func TestTenantScopeIsolatesTenants(t *testing.T) {
+	db, _, all, _, mine, theirs := openTenantsDB(t)
+
+	// Children go first so that deleting a tenant's rows leaves no dangling references
+	t.Run("bin_stocks", func(t *testing.T) {
+		checkIsolation[models.BinStock](t, db, all,
+			[]interface{}{"product_id = ? AND bin_id = ?", mine.stock.ProductID, mine.stock.BinID},
+			[]interface{}{"product_id = ? AND bin_id = ?", theirs.stock.ProductID, theirs.stock.BinID},
+			"quantity", 99)
+	})
+	t.Run("orders", func(t *testing.T) {
+		checkIsolation[models.Order](t, db, all,
+			[]interface{}{"order_id = ?", mine.order.OrderID},
+			[]interface{}{"order_id = ?", theirs.order.OrderID},
+			"quantity", 99)
+	})
+	t.Run("webhooks", func(t *testing.T) {
+		checkIsolation[models.Webhook](t, db, all,
+			[]interface{}{"id = ?", mine.webhook.ID},
+			[]interface{}{"id = ?", theirs.webhook.ID},
+			"url", "http://example.com/changed")
+	})
+	t.Run("bins", func(t *testing.T) {
+		checkIsolation[models.Bin](t, db, all,
+			[]interface{}{"id = ?", mine.bin.ID},
+			[]interface{}{"id = ?", theirs.bin.ID},
+			"zone", "changed")
+	})
+	t.Run("products", func(t *testing.T) {
+		checkIsolation[models.Product](t, db, all,
+			[]interface{}{"id = ?", mine.product.ID},
+			[]interface{}{"id = ?", theirs.product.ID},
+			"name", "Changed")
+	})
+}
+
+func TestTenantScopeStampsCreatedRecords(t *testing.T) {
+	_, second, all, _, _, theirs := openTenantsDB(t)
+	secondID, _ := TenantFrom(second.Statement.Context)
+
+	check := func(name string, model interface{}, where ...interface{}) {
+		t.Helper()
+		var tenantIDs []uint
+		if err := all.Model(model).Where(where[0], where[1:]...).Pluck("tenant_id", &tenantIDs).Error; err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		if len(tenantIDs) != 1 || tenantIDs[0] != secondID {
+			t.Errorf("%s was stored for tenants %v, want %d", name, tenantIDs, secondID)
+		}
+	}
+	check("product", &models.Product{}, "id = ?", theirs.product.ID)
+	check("order", &models.Order{}, "order_id = ?", theirs.order.OrderID)
+	check("bin", &models.Bin{}, "id = ?", theirs.bin.ID)
+	check("bin stock", &models.BinStock{}, "product_id = ? AND bin_id = ?", theirs.stock.ProductID, theirs.stock.BinID)
+	check("webhook", &models.Webhook{}, "id = ?", theirs.webhook.ID)
+
+	// A record cannot be written into another tenant through its TenantID
+	product := models.Product{TenantID: DefaultTenantID, Name: "Smuggled", Category: "Furniture"}
+	if err := second.Create(&product).Error; err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	if product.TenantID != secondID {
+		t.Errorf("product created under tenant %d kept tenant %d", secondID, product.TenantID)
+	}
+	check("smuggled product", &models.Product{}, "id = ?", product.ID)
+}
+
+func TestTenantScopeRequiresTenant(t *testing.T) {
+	_, _, all, unscoped, mine, _ := openTenantsDB(t)
+
+	var products []models.Product
+	if err := unscoped.Find(&products).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("find without a tenant returned %v, want ErrTenantRequired", err)
+	}
+	var product models.Product
+	if err := unscoped.First(&product, mine.product.ID).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("first without a tenant returned %v, want ErrTenantRequired", err)
+	}
+	var count int64
+	if err := unscoped.Model(&models.Bin{}).Count(&count).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("count without a tenant returned %v, want ErrTenantRequired", err)
+	}
+	if err := unscoped.Model(&models.Webhook{}).Where("id = ?", mine.webhook.ID).Update("active", false).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("update without a tenant returned %v, want ErrTenantRequired", err)
+	}
+	if err := unscoped.Where("order_id = ?", mine.order.OrderID).Delete(&models.Order{}).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("delete without a tenant returned %v, want ErrTenantRequired", err)
+	}
+	if err := unscoped.Create(&models.Bin{Location: "Store 2", Code: "B-01"}).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("create without a tenant returned %v, want ErrTenantRequired", err)
+	}
+
+	// Nothing was changed by the rejected statements
+	if err := all.Model(&models.Order{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("%d orders are left (%v), want 2", count, err)
+	}
+	if err := all.Model(&models.Webhook{}).Where("active = ?", true).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("%d webhooks are active (%v), want 2", count, err)
+	}
+	if err := all.Model(&models.Bin{}).Where("location = ?", "Store 2").Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("%d bins were created without a tenant (%v), want none", count, err)
+	}
+}
+
+func TestAllTenantsCreateNeedsTenantID(t *testing.T) {
+	_, second, all, _, _, _ := openTenantsDB(t)
+	secondID, _ := TenantFrom(second.Statement.Context)
+
+	if err := all.Create(&models.Product{Name: "Orphan", Category: "Furniture"}).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("creating a product without a tenant returned %v, want ErrTenantRequired", err)
+	}
+	bins := []models.Bin{
+		{TenantID: secondID, Location: "Store 3", Code: "C-01"},
+		{Location: "Store 3", Code: "C-02"},
+	}
+	if err := all.Create(&bins).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Errorf("creating bins one of which has no tenant returned %v, want ErrTenantRequired", err)
+	}
+
+	var count int64
+	if err := all.Model(&models.Product{}).Where("name = ?", "Orphan").Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("%d products were created without a tenant (%v), want none", count, err)
+	}
+	if err := all.Model(&models.Bin{}).Where("location = ?", "Store 3").Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("%d bins were created without a tenant (%v), want none", count, err)
+	}
+
+	product := models.Product{TenantID: secondID, Name: "Owned", Category: "Furniture"}
+	if err := all.Create(&product).Error; err != nil {
+		t.Fatalf("creating a product with a tenant: %v", err)
+	}
+	if err := second.First(&models.Product{}, product.ID).Error; err != nil {
+		t.Errorf("the product is not visible to its tenant: %v", err)
+	}
+}
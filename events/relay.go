@@ -0,0 +1,116 @@
+// events/relay.go
+package events
+
+import (
+	"context"
+	"fmt"
+	"inventory_system/config"
+	"inventory_system/database"
+	"inventory_system/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxRetryDelay caps the wait before an event that failed to publish is tried again
+const maxRetryDelay = time.Hour
+
+// batchSize is how many unpublished events one query reads
+const batchSize = 500
+
+// Relay publishes outbox events to its sinks. Delivery is at least once: an
+// event is marked published only after every sink has taken it, and a failure
+// at any sink publishes it to all of them again on the next try. Events of the
+// same aggregate are published in the order they were recorded; one that
+// fails holds back the later events of its aggregate but not those of others.
+// Only one relay should run against a database, or two could publish the
+// events of an aggregate out of order.
+type Relay struct {
+	Sinks []Sink
+	// RetryDelay is the wait after the first failed attempt; it doubles after each
+	RetryDelay time.Duration
+}
+
+// NewRelay builds a relay with the sinks and retry delay of the service configuration
+func NewRelay(cfg *config.Config) (*Relay, error) {
+	sinks, err := NewSinks(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Relay{Sinks: sinks, RetryDelay: time.Duration(cfg.Outbox.RetryDelay)}, nil
+}
+
+// RunDue publishes unpublished events of every tenant, oldest first. Events
+// are read a batch at a time until none are left, so that the later events of
+// held aggregates cannot fill a batch and starve the events of others.
+func (r *Relay) RunDue(ctx context.Context, db *gorm.DB, now time.Time) error {
+	held := make(map[string]bool)
+	var lastID uint
+	for {
+		var events []models.OutboxEvent
+		if err := db.WithContext(database.AllTenants(ctx)).
+			Where("published_at IS NULL AND id > ?", lastID).
+			Order("id").Limit(batchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			aggregate := fmt.Sprintf("%d/%s/%s", event.TenantID, event.AggregateType, event.AggregateID)
+			if held[aggregate] {
+				continue
+			}
+			if event.NextAttemptAt != nil && event.NextAttemptAt.After(now) {
+				held[aggregate] = true
+				continue
+			}
+
+			tenantDB := db.WithContext(database.WithTenant(ctx, event.TenantID))
+			if err := r.publish(ctx, tenantDB, &event, now); err != nil {
+				held[aggregate] = true
+				log.Printf("Publishing outbox event %d failed: %v", event.ID, err)
+			}
+		}
+
+		if len(events) < batchSize {
+			return nil
+		}
+		lastID = events[len(events)-1].ID
+	}
+}
+
+// publish sends an event to every sink and records the outcome
+func (r *Relay) publish(ctx context.Context, db *gorm.DB, event *models.OutboxEvent, now time.Time) error {
+	var err error
+	for _, sink := range r.Sinks {
+		if err = sink.Publish(ctx, db, *event); err != nil {
+			err = fmt.Errorf("%s sink: %w", sink.Name(), err)
+			break
+		}
+	}
+
+	event.Attempts++
+	updates := map[string]interface{}{"attempts": event.Attempts, "error": "", "next_attempt_at": nil, "published_at": now}
+	if err != nil {
+		updates["error"] = err.Error()
+		updates["next_attempt_at"] = now.Add(r.backoff(event.Attempts))
+		updates["published_at"] = nil
+	}
+	if saveErr := db.Model(&models.OutboxEvent{}).Where("id = ?", event.ID).UpdateColumns(updates).Error; saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// backoff returns the wait before retrying an event that has failed attempts times
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.RetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,75 @@
+// events/relay_test.go
+package events
+
+import (
+	"context"
+	"inventory_system/config"
+	"inventory_system/database"
+	"inventory_system/models"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recordingSink takes every event and remembers the IDs it took
+type recordingSink struct {
+	published []uint
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Publish(ctx context.Context, db *gorm.DB, event models.OutboxEvent) error {
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func TestRunDuePagesPastHeldAggregates(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.Path = filepath.Join(t.TempDir(), "test.db")
+	cfg.Log.Level = "silent"
+
+	db, err := database.Connect(&cfg)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	// One aggregate waits to retry with more than a batch of events behind it,
+	// and another aggregate has an event after them
+	now := time.Now()
+	retryAt := now.Add(time.Hour)
+	var events []models.OutboxEvent
+	for i := 0; i <= batchSize; i++ {
+		event := models.OutboxEvent{TenantID: database.DefaultTenantID, AggregateType: "product", AggregateID: "1", Event: "product.updated", Payload: "{}"}
+		if i == 0 {
+			event.Attempts = 1
+			event.NextAttemptAt = &retryAt
+		}
+		events = append(events, event)
+	}
+	events = append(events, models.OutboxEvent{TenantID: database.DefaultTenantID, AggregateType: "product", AggregateID: "2", Event: "product.created", Payload: "{}"})
+	if err := db.WithContext(database.AllTenants(context.Background())).CreateInBatches(&events, 100).Error; err != nil {
+		t.Fatalf("recording events: %v", err)
+	}
+
+	sink := &recordingSink{}
+	relay := &Relay{Sinks: []Sink{sink}, RetryDelay: time.Minute}
+	if err := relay.RunDue(context.Background(), db, now); err != nil {
+		t.Fatalf("running the relay: %v", err)
+	}
+
+	last := events[len(events)-1].ID
+	if len(sink.published) != 1 || sink.published[0] != last {
+		t.Errorf("published events %v, want only %d of the other aggregate", sink.published, last)
+	}
+}
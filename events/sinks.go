@@ -0,0 +1,215 @@
+// events/sinks.go
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"inventory_system/config"
+	"inventory_system/models"
+	"inventory_system/webhooks"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Outbox sinks
+const (
+	SinkWebhook = "webhook"
+	SinkFile    = "file"
+	SinkRedis   = "redis"
+)
+
+// Sink publishes outbox events. Publish may be called again for an event it
+// has already taken, so consumers must tolerate duplicates.
+type Sink interface {
+	Name() string
+	// Publish sends an event; db is scoped to the event's tenant
+	Publish(ctx context.Context, db *gorm.DB, event models.OutboxEvent) error
+}
+
+// Message is how the file and Redis sinks write an event
+type Message struct {
+	ID            uint            `json:"id"`
+	TenantID      uint            `json:"tenant_id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Event         string          `json:"event"`
+	Data          json.RawMessage `json:"data"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// newMessage describes an event for the file and Redis sinks
+func newMessage(event models.OutboxEvent) Message {
+	return Message{
+		ID:            event.ID,
+		TenantID:      event.TenantID,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Event:         event.Event,
+		Data:          json.RawMessage(event.Payload),
+		OccurredAt:    event.CreatedAt,
+	}
+}
+
+// NewSinks builds the sinks named in the service configuration
+func NewSinks(cfg *config.Config) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range strings.Split(cfg.Outbox.Sinks, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case SinkWebhook:
+			sinks = append(sinks, WebhookSink{})
+		case SinkFile:
+			sinks = append(sinks, &FileSink{Path: cfg.Outbox.File})
+		case SinkRedis:
+			sinks = append(sinks, &RedisSink{Addr: cfg.Outbox.RedisAddr, Stream: cfg.Outbox.RedisStream, Timeout: 10 * time.Second})
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// WebhookSink queues events for delivery to the tenant's webhooks, which the
+// webhook dispatcher then sends and retries
+type WebhookSink struct{}
+
+// Name returns the sink's name
+func (WebhookSink) Name() string { return SinkWebhook }
+
+// Publish queues the event's webhook deliveries
+func (WebhookSink) Publish(ctx context.Context, db *gorm.DB, event models.OutboxEvent) error {
+	return webhooks.Enqueue(db, event)
+}
+
+// FileSink appends events to a file as JSON lines
+type FileSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Name returns the sink's name
+func (s *FileSink) Name() string { return SinkFile }
+
+// Publish appends the event and syncs the file, so that an event is on disk
+// before it is marked published
+func (s *FileSink) Publish(ctx context.Context, db *gorm.DB, event models.OutboxEvent) error {
+	line, err := json.Marshal(newMessage(event))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// RedisSink adds events to a Redis stream with XADD. It speaks just enough of
+// the Redis protocol for that, so any Redis-compatible server, such as a local
+// Redis, Valkey or an in-memory stand-in, can take the events. Each event is
+// added with its tenant, aggregate, event name and the message as JSON.
+type RedisSink struct {
+	Addr    string
+	Stream  string
+	Timeout time.Duration
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+// Name returns the sink's name
+func (s *RedisSink) Name() string { return SinkRedis }
+
+// Publish adds the event to the stream and waits for the server to confirm it
+func (s *RedisSink) Publish(ctx context.Context, db *gorm.DB, event models.OutboxEvent) error {
+	message, err := json.Marshal(newMessage(event))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		dialer := net.Dialer{Timeout: s.Timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+		if err != nil {
+			return err
+		}
+		s.conn, s.reader = conn, bufio.NewReader(conn)
+	}
+
+	err = s.command("XADD", s.Stream, "*",
+		"tenant_id", strconv.FormatUint(uint64(event.TenantID), 10),
+		"aggregate", event.AggregateType+":"+event.AggregateID,
+		"event", event.Event,
+		"message", string(message))
+	if err != nil {
+		// Start over on a new connection, as the reply may still be on its way
+		s.conn.Close()
+		s.conn, s.reader = nil, nil
+	}
+	return err
+}
+
+// command sends a command and reads its reply, returning Redis errors
+func (s *RedisSink) command(args ...string) error {
+	var request strings.Builder
+	fmt.Fprintf(&request, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&request, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	s.conn.SetDeadline(time.Now().Add(s.Timeout))
+	if _, err := s.conn.Write([]byte(request.String())); err != nil {
+		return err
+	}
+	reply, err := s.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	reply = strings.TrimRight(reply, "\r\n")
+
+	switch {
+	case strings.HasPrefix(reply, "-"):
+		return fmt.Errorf("redis: %s", reply[1:])
+	case strings.HasPrefix(reply, "$"):
+		// XADD replies with the ID of the entry as a bulk string
+		length, err := strconv.Atoi(reply[1:])
+		if err != nil {
+			return fmt.Errorf("redis: unexpected reply %q", reply)
+		}
+		if length >= 0 {
+			_, err = s.reader.Discard(length + 2)
+		}
+		return err
+	case strings.HasPrefix(reply, "+"):
+		return nil
+	default:
+		return fmt.Errorf("redis: unexpected reply %q", reply)
+	}
+}
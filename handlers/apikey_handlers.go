@@ -0,0 +1,108 @@
+// handlers/apikey_handlers.go
+package handlers
+
+import (
+	"errors"
+	"inventory_system/auth"
+	"inventory_system/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	Keys services.APIKeyService
+}
+
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	Locations []string   `json:"locations"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// GetAPIKeys lists the API keys, including revoked and expired ones
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.Keys.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey issues a key; the key is only ever returned in this response
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var input CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A key cannot grant more than its creator holds
+	current := auth.CurrentUser(c)
+	for _, scope := range input.Scopes {
+		if current != nil && auth.IsValidPermission(scope) && !current.Can(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You cannot grant " + scope})
+			return
+		}
+	}
+	if current != nil && len(current.Locations) > 0 {
+		if len(input.Locations) == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Locations are required because you are limited to some locations"})
+			return
+		}
+		for _, location := range input.Locations {
+			if !allowLocation(c, location) {
+				return
+			}
+		}
+	}
+
+	record, key, err := h.Keys.Create(c.Request.Context(), services.APIKeyRequest{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		Locations: input.Locations,
+		ExpiresAt: input.ExpiresAt,
+	})
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNameRequired),
+		errors.Is(err, services.ErrAPIKeyScopesRequired),
+		errors.Is(err, services.ErrInvalidScope),
+		errors.Is(err, services.ErrAPIKeyExpiryPast):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"api_key": record, "key": key})
+}
+
+// RevokeAPIKey stops a key from authenticating; its record is kept
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	record, err := h.Keys.Revoke(c.Request.Context(), uint(id))
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	case errors.Is(err, services.ErrAPIKeyRevoked):
+		c.JSON(http.StatusConflict, gin.H{"error": "API key is already revoked"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
@@ -0,0 +1,48 @@
+// handlers/audit_handlers.go
+package handlers
+
+import (
+	"inventory_system/database"
+	"inventory_system/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AuditHandler struct {
+	DB *gorm.DB
+}
+
+// GetAuditEntries lists audit entries, newest first, filtered by entity, entity
+// ID, actor, request ID and a from/to date range
+func (h *AuditHandler) GetAuditEntries(c *gin.Context) {
+	entity := c.Query("entity")
+	if entity != "" && entity != models.EntityProduct && entity != models.EntityInventory && entity != models.EntityOrder {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity, use product, inventory or order"})
+		return
+	}
+
+	from, to, err := parseDateRange(c, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := database.GetAuditEntries(h.DB.WithContext(c.Request.Context()), database.AuditFilter{
+		Entity:    entity,
+		EntityID:  c.Query("entity_id"),
+		Actor:     c.Query("actor"),
+		RequestID: c.Query("request_id"),
+		From:      from,
+		To:        to,
+		Limit:     positiveQuery(c, "limit", 100),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
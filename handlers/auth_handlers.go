@@ -0,0 +1,100 @@
+// handlers/auth_handlers.go
+package handlers
+
+import (
+	"errors"
+	"inventory_system/auth"
+	"inventory_system/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	Users  services.UserService
+	Tokens *auth.Tokens
+}
+
+type LoginInput struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Login exchanges a username and password for an access and a refresh token
+func (h *AuthHandler) Login(c *gin.Context) {
+	var input LoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.Users.Authenticate(input.Username, input.Password)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
+	tokens, err := h.Tokens.Issue(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh exchanges a refresh token for a new pair of tokens, as long as the
+// user is still active
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := h.Tokens.Parse(input.RefreshToken, auth.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+
+	user, err := h.Users.Get(claims.UserID())
+	if errors.Is(err, services.ErrUserNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh tokens"})
+		return
+	}
+
+	tokens, err := h.Tokens.Issue(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// allowLocation responds with 403 and returns false when the signed-in user may
+// not change stock at a location
+func allowLocation(c *gin.Context, location string) bool {
+	if auth.CurrentUser(c).CanAccessLocation(location) {
+		return true
+	}
+	if location == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "A location is required because you are limited to some locations"})
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "You may not change stock at " + location})
+	return false
+}
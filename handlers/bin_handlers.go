@@ -0,0 +1,212 @@
+// handlers/bin_handlers.go
+package handlers
+
+import (
+	"errors"
+	"inventory_system/models"
+	"inventory_system/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BinHandler struct {
+	Bins services.BinService
+}
+
+type CreateBinInput struct {
+	Location string `json:"location" binding:"required"`
+	Zone     string `json:"zone"`
+	Aisle    string `json:"aisle"`
+	Shelf    string `json:"shelf"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type PutawayInput struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	BinID     uint `json:"bin_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+}
+
+type MoveStockInput struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	FromBinID uint `json:"from_bin_id" binding:"required"`
+	ToBinID   uint `json:"to_bin_id" binding:"required,nefield=FromBinID"`
+	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+}
+
+// GetBins retrieves bins with optional location filtering
+func (h *BinHandler) GetBins(c *gin.Context) {
+	bins, err := h.Bins.List(c.Request.Context(), c.Query("location"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bins"})
+		return
+	}
+
+	c.JSON(http.StatusOK, bins)
+}
+
+// GetBin retrieves a single bin together with the stock it holds
+func (h *BinHandler) GetBin(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bin not found"})
+		return
+	}
+	bin, ok := h.findBin(c, uint(id), "Bin not found")
+	if !ok {
+		return
+	}
+
+	stock, err := h.Bins.Stock(c.Request.Context(), bin.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bin stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bin": bin, "stock": stock})
+}
+
+// CreateBin adds a new bin to a location
+func (h *BinHandler) CreateBin(c *gin.Context) {
+	var input CreateBinInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !allowLocation(c, input.Location) {
+		return
+	}
+
+	bin := models.Bin{
+		Location: input.Location,
+		Zone:     input.Zone,
+		Aisle:    input.Aisle,
+		Shelf:    input.Shelf,
+		Code:     input.Code,
+	}
+
+	err := h.Bins.Create(c.Request.Context(), &bin)
+	switch {
+	case errors.Is(err, services.ErrBinCodeReserved):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bin code is reserved"})
+	case errors.Is(err, services.ErrUnknownLocation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown location"})
+	case errors.Is(err, services.ErrBinExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Bin already exists at this location"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bin"})
+	default:
+		c.JSON(http.StatusCreated, bin)
+	}
+}
+
+// Putaway moves received stock from a location's receiving bin into a storage bin
+func (h *BinHandler) Putaway(c *gin.Context) {
+	var input PutawayInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bin, ok := h.findBin(c, input.BinID, "Bin not found")
+	if !ok {
+		return
+	}
+	if !allowLocation(c, bin.Location) {
+		return
+	}
+
+	err := h.Bins.Putaway(c.Request.Context(), services.PutawayRequest{
+		ProductID: input.ProductID,
+		BinID:     bin.ID,
+		Quantity:  input.Quantity,
+	})
+	switch {
+	case errors.Is(err, services.ErrBinNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bin not found"})
+		return
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	case errors.Is(err, services.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": "Insufficient stock in receiving"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to put away stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Stock put away successfully",
+		"product_id": input.ProductID,
+		"bin_id":     bin.ID,
+		"quantity":   input.Quantity,
+	})
+}
+
+// MoveStock transfers stock of a product from one bin to another
+func (h *BinHandler) MoveStock(c *gin.Context) {
+	var input MoveStockInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, ok := h.findBin(c, input.FromBinID, "Source bin not found")
+	if !ok {
+		return
+	}
+	to, ok := h.findBin(c, input.ToBinID, "Destination bin not found")
+	if !ok {
+		return
+	}
+	if !allowLocation(c, from.Location) || !allowLocation(c, to.Location) {
+		return
+	}
+
+	err := h.Bins.Move(c.Request.Context(), services.BinMoveRequest{
+		ProductID: input.ProductID,
+		FromBinID: from.ID,
+		ToBinID:   to.ID,
+		Quantity:  input.Quantity,
+	})
+	switch {
+	case errors.Is(err, services.ErrBinNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bin not found"})
+		return
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	case errors.Is(err, services.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": "Insufficient stock in source bin"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Stock moved successfully",
+		"product_id":  input.ProductID,
+		"from_bin_id": from.ID,
+		"to_bin_id":   to.ID,
+		"quantity":    input.Quantity,
+	})
+}
+
+// findBin loads a bin, answering with notFound when there is none
+func (h *BinHandler) findBin(c *gin.Context, id uint, notFound string) (models.Bin, bool) {
+	bin, err := h.Bins.Get(c.Request.Context(), id)
+	if errors.Is(err, services.ErrBinNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return bin, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bin"})
+		return bin, false
+	}
+	return bin, true
+}
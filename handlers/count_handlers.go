@@ -0,0 +1,257 @@
+// handlers/count_handlers.go
+package handlers
+
+import (
+	"errors"
+	"inventory_system/models"
+	"inventory_system/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CountHandler struct {
+	Counts services.CountService
+}
+
+type CreateCountInput struct {
+	Location   string `json:"location"`
+	ProductIDs []uint `json:"product_ids"`
+	Note       string `json:"note"`
+}
+
+type ScheduleCountInput struct {
+	Location string `json:"location" binding:"required"`
+	Limit    int    `json:"limit" binding:"omitempty,gte=0"`
+}
+
+type CountEntry struct {
+	LineID   uint `json:"line_id" binding:"required"`
+	Quantity *int `json:"quantity" binding:"required,gte=0"`
+}
+
+type SubmitCountsInput struct {
+	Entries []CountEntry `json:"entries" binding:"required,min=1,dive"`
+}
+
+// GetCounts retrieves count sessions with optional status filtering
+func (h *CountHandler) GetCounts(c *gin.Context) {
+	sessions, err := h.Counts.List(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve count sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// GetCount retrieves a count session as a blind count sheet without expected quantities
+func (h *CountHandler) GetCount(c *gin.Context) {
+	session, ok := h.findSession(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// CreateCount opens a count session and snapshots the expected quantities
+func (h *CountHandler) CreateCount(c *gin.Context) {
+	var input CreateCountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Location == "" && len(input.ProductIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A location or a list of products is required"})
+		return
+	}
+	// Users restricted to some locations must count one of them, not every location
+	if !allowLocation(c, input.Location) {
+		return
+	}
+
+	session, err := h.Counts.Open(c.Request.Context(), services.CountRequest{
+		Location:   input.Location,
+		ProductIDs: input.ProductIDs,
+		Note:       input.Note,
+	})
+	switch {
+	case errors.Is(err, services.ErrNothingToCount):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No inventory matches the count scope"})
+	case errors.Is(err, services.ErrCountOverlap):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create count session"})
+	default:
+		c.JSON(http.StatusCreated, session)
+	}
+}
+
+// ScheduleCount opens a count session for the items at a location that are due
+// for counting, so that A items are counted more often than B and C items
+func (h *CountHandler) ScheduleCount(c *gin.Context) {
+	var input ScheduleCountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !allowLocation(c, input.Location) {
+		return
+	}
+
+	session, err := h.Counts.Schedule(c.Request.Context(), input.Location, input.Limit)
+	switch {
+	case errors.Is(err, services.ErrNothingToCount):
+		c.JSON(http.StatusOK, gin.H{"message": "No items are due for counting at this location"})
+	case errors.Is(err, services.ErrCountOverlap):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule count session"})
+	default:
+		c.JSON(http.StatusCreated, session)
+	}
+}
+
+// SubmitCounts records blind counts entered by staff
+func (h *CountHandler) SubmitCounts(c *gin.Context) {
+	session, ok := h.findOpenSession(c)
+	if !ok {
+		return
+	}
+
+	var input SubmitCountsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries := make([]services.CountEntry, len(input.Entries))
+	for i, entry := range input.Entries {
+		entries[i] = services.CountEntry{LineID: entry.LineID, Quantity: *entry.Quantity}
+	}
+
+	err := h.Counts.Submit(c.Request.Context(), session.ID, entries)
+	switch {
+	case errors.Is(err, services.ErrCountNotOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": "Count session is no longer open"})
+		return
+	case errors.Is(err, services.ErrCountLineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Count line not found in this session"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record counts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Counts recorded successfully", "entries": len(input.Entries)})
+}
+
+// GetVariance reports expected against counted quantities for a count session
+func (h *CountHandler) GetVariance(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Count session not found"})
+		return
+	}
+
+	report, err := h.Counts.Variance(c.Request.Context(), uint(id))
+	if errors.Is(err, services.ErrCountNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Count session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build variance report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ApproveCount posts the variances of a fully counted session and closes it
+func (h *CountHandler) ApproveCount(c *gin.Context) {
+	session, ok := h.findOpenSession(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.Counts.Approve(c.Request.Context(), session.ID)
+	switch {
+	case errors.Is(err, services.ErrCountIncomplete):
+		c.JSON(http.StatusConflict, gin.H{"error": "Count session has uncounted lines", "uncounted_lines": report.UncountedLines})
+		return
+	case errors.Is(err, services.ErrCountNotOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": "Count session is " + report.Session.Status})
+		return
+	case errors.Is(err, services.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": "Stock changed during the count and the variance can no longer be posted"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post count adjustments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// CancelCount closes a count session without posting any adjustments
+func (h *CountHandler) CancelCount(c *gin.Context) {
+	session, ok := h.findOpenSession(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.Counts.Cancel(c.Request.Context(), session.ID)
+	switch {
+	case errors.Is(err, services.ErrCountNotOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": "Count session is " + session.Status})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel count session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// findSession loads the count session from the route
+func (h *CountHandler) findSession(c *gin.Context) (models.CountSession, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Count session not found"})
+		return models.CountSession{}, false
+	}
+
+	session, err := h.Counts.Get(c.Request.Context(), uint(id))
+	if errors.Is(err, services.ErrCountNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Count session not found"})
+		return session, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve count session"})
+		return session, false
+	}
+	return session, true
+}
+
+// findOpenSession loads the count session from the route and makes sure it is
+// still open and at a location the user may change
+func (h *CountHandler) findOpenSession(c *gin.Context) (models.CountSession, bool) {
+	session, ok := h.findSession(c)
+	if !ok {
+		return session, false
+	}
+
+	if session.Status != models.CountOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Count session is " + session.Status})
+		return session, false
+	}
+
+	if !allowLocation(c, session.Location) {
+		return session, false
+	}
+
+	return session, true
+}
@@ -0,0 +1,114 @@
+// handlers/forecast_handlers.go
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"inventory_system/database"
+	"inventory_system/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ForecastHandler struct {
+	DB *gorm.DB
+	// Location is the time zone demand is bucketed into days in
+	Location *time.Location
+}
+
+// GetForecast returns the daily demand forecast of a product with confidence intervals
+func (h *ForecastHandler) GetForecast(c *gin.Context) {
+	product, history, start, horizon, ok := h.loadHistory(c)
+	if !ok {
+		return
+	}
+
+	method := c.DefaultQuery("method", database.ForecastAuto)
+	if !database.IsValidForecastMethod(method) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "method must be one of auto, " + strings.Join(database.ForecastMethods, ", ")})
+		return
+	}
+
+	confidence, err := strconv.ParseFloat(c.DefaultQuery("confidence", "0.95"), 64)
+	if err != nil || !database.IsValidConfidence(confidence) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "confidence must be one of 0.8, 0.9, 0.95 or 0.99"})
+		return
+	}
+
+	forecast, err := database.ForecastDemand(history, method, horizon, confidence, start)
+	if errors.Is(err, database.ErrInsufficientHistory) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to forecast demand"})
+		return
+	}
+	forecast.ProductID = product.ID
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// GetBacktest reports the forecast error of every method on held-out recent history
+func (h *ForecastHandler) GetBacktest(c *gin.Context) {
+	product, history, _, horizon, ok := h.loadHistory(c)
+	if !ok {
+		return
+	}
+
+	folds := positiveQuery(c, "folds", 1)
+	if folds > database.MaxBacktestFolds {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("folds must not exceed %d", database.MaxBacktestFolds)})
+		return
+	}
+
+	backtest := database.BacktestDemand(history, horizon, folds)
+	backtest.ProductID = product.ID
+
+	c.JSON(http.StatusOK, backtest)
+}
+
+// loadHistory reads the product, horizon and history_days parameters and loads the
+// daily demand history, writing an error response when any of them is invalid
+func (h *ForecastHandler) loadHistory(c *gin.Context) (models.Product, []float64, time.Time, int, bool) {
+	var product models.Product
+	if err := h.DB.WithContext(c.Request.Context()).First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return product, nil, time.Time{}, 0, false
+	}
+
+	horizon, err := parseHorizon(c.DefaultQuery("horizon", "30d"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return product, nil, time.Time{}, 0, false
+	}
+	if horizon > database.MaxForecastHorizonDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("horizon must not exceed %d days", database.MaxForecastHorizonDays)})
+		return product, nil, time.Time{}, 0, false
+	}
+
+	historyDays := positiveQuery(c, "history_days", 365)
+	if historyDays > database.MaxForecastHistoryDays {
+		historyDays = database.MaxForecastHistoryDays
+	}
+
+	history, start, err := database.GetDailyDemand(h.DB.WithContext(c.Request.Context()), product.ID, historyDays, time.Now(), h.location())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load order history"})
+		return product, nil, time.Time{}, 0, false
+	}
+	return product, history, start, horizon, true
+}
+
+// location returns the configured time zone, defaulting to local time
+func (h *ForecastHandler) location() *time.Location {
+	if h.Location == nil {
+		return time.Local
+	}
+	return h.Location
+}
@@ -0,0 +1,122 @@
+// handlers/handlers_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"inventory_system/models"
+	"inventory_system/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve sends a request with a JSON body to a single route and returns the response
+func serve(method, route string, handler gin.HandlerFunc, path, body string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Handle(method, route, handler)
+
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestCreateOrderInsufficientStock(t *testing.T) {
+	store := services.NewMemoryStore()
+	product := store.PutProduct(models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25})
+	store.SetStock(product.ID, services.DefaultLocation, 2)
+	handler := &OrderHandler{Orders: &services.MemoryOrderService{Store: store}}
+
+	response := serve(http.MethodPost, "/orders", handler.CreateOrder, "/orders", `{"product_id":1,"quantity":3}`)
+	if response.Code != http.StatusConflict || !strings.Contains(response.Body.String(), "Insufficient stock") {
+		t.Fatalf("got %d %s, want 409 Insufficient stock", response.Code, response.Body)
+	}
+	if stock := store.Stock(product.ID, services.DefaultLocation); stock != 2 {
+		t.Errorf("stock is %d after a rejected order, want 2", stock)
+	}
+
+	response = serve(http.MethodPost, "/orders", handler.CreateOrder, "/orders", `{"product_id":1,"quantity":2}`)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("got %d %s, want 201", response.Code, response.Body)
+	}
+	var order models.Order
+	if err := json.Unmarshal(response.Body.Bytes(), &order); err != nil || order.Location != services.DefaultLocation || order.TotalPrice != 50 {
+		t.Errorf("created order %s, want 2 units from %s for 50", response.Body, services.DefaultLocation)
+	}
+}
+
+func TestAdjustStockRequiresReason(t *testing.T) {
+	store := services.NewMemoryStore()
+	product := store.PutProduct(models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25})
+	store.SetStock(product.ID, "Store 1", 10)
+	handler := &InventoryHandler{Inventory: &services.MemoryInventoryService{Store: store}}
+
+	tests := []struct {
+		name  string
+		body  string
+		code  int
+		stock int
+	}{
+		{"missing reason", `{"action":"remove","value":3}`, http.StatusBadRequest, 10},
+		{"unknown reason", `{"action":"remove","value":3,"reason":"lost"}`, http.StatusBadRequest, 10},
+		{"count reason", `{"action":"remove","value":3,"reason":"count"}`, http.StatusBadRequest, 10},
+		{"damage", `{"action":"remove","value":3,"reason":"damage"}`, http.StatusOK, 7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := serve(http.MethodPatch, "/inventory/:product_id", handler.AdjustStock, "/inventory/1?location=Store%201", test.body)
+			if response.Code != test.code {
+				t.Errorf("got %d %s, want %d", response.Code, response.Body, test.code)
+			}
+			if stock := store.Stock(product.ID, "Store 1"); stock != test.stock {
+				t.Errorf("stock is %d, want %d", stock, test.stock)
+			}
+		})
+	}
+}
+
+func TestGetProductsFiltersByAbcClass(t *testing.T) {
+	store := services.NewMemoryStore()
+	store.PutProduct(models.Product{Name: "Desk Lamp", Category: "Furniture", AbcClass: "A"})
+	store.PutProduct(models.Product{Name: "Sofa", Category: "Furniture", AbcClass: "C"})
+	store.PutProduct(models.Product{Name: "Phone", Category: "Electronics", AbcClass: "A"})
+	handler := &ProductHandler{Products: &services.MemoryProductService{Store: store}}
+
+	response := serve(http.MethodGet, "/products", handler.GetProducts, "/products?abc_class=A", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", response.Code, response.Body)
+	}
+	var products []models.Product
+	if err := json.Unmarshal(response.Body.Bytes(), &products); err != nil {
+		t.Fatalf("decoding products: %v", err)
+	}
+	var names []string
+	for _, product := range products {
+		names = append(names, product.Name)
+	}
+	if strings.Join(names, ",") != "Desk Lamp,Phone" {
+		t.Errorf("got products %v, want Desk Lamp and Phone", names)
+	}
+}
+
+func TestParseAsOfEndsTheDayInTheReportZone(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	asOf, err := parseAsOf("2026-03-31", tokyo)
+	if err != nil {
+		t.Fatalf("parsing: %v", err)
+	}
+	want := time.Date(2026, 4, 1, 0, 0, 0, 0, tokyo).Add(-time.Nanosecond)
+	if !asOf.Equal(want) {
+		t.Errorf("as_of 2026-03-31 in Tokyo is %s, want %s", asOf, want)
+	}
+}
@@ -2,37 +2,60 @@
 package handlers
 
 import (
+	"errors"
 	"inventory_system/database"
 	"inventory_system/models"
+	"inventory_system/services"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type InventoryHandler struct {
-	DB *gorm.DB
+	// DB is used for the stock reports
+	DB        *gorm.DB
+	Inventory services.InventoryService
+	// Location is the time zone as_of dates are read in
+	Location *time.Location
 }
 
 type StockAdjustment struct {
 	Action string `json:"action" binding:"required,oneof=add remove"`
 	Value  int    `json:"value" binding:"required,gt=0"`
+	Reason string `json:"reason" binding:"required,oneof=damage theft found correction sample"`
+	Note   string `json:"note"`
+	// UnitCost is the cost of each received unit; it defaults to the current cost
+	UnitCost *float64 `json:"unit_cost" binding:"omitempty,gte=0"`
 }
 
-// GetInventory retrieves inventory information with optional product filtering
+type AdjustmentDecision struct {
+	Note string `json:"note"`
+}
+
+// GetInventory retrieves inventory information with optional product and location
+// filtering, either current or as of a past date
 func (h *InventoryHandler) GetInventory(c *gin.Context) {
-	var inventories []models.Inventory
-	db := h.DB
+	asOf, err := optionalAsOf(c, h.location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Apply product filter if provided
-	if productID := c.Query("product_id"); productID != "" {
-		db = db.Where("product_id = ?", productID)
+	filter := services.InventoryFilter{Location: c.Query("location"), AsOf: asOf}
+	if value := c.Query("product_id"); value != "" {
+		productID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+			return
+		}
+		filter.ProductID = uint(productID)
 	}
 
-	// Join with products to get more information
-	result := db.Preload("Product").Find(&inventories)
-	if result.Error != nil {
+	inventories, err := h.Inventory.List(c.Request.Context(), filter)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve inventory"})
 		return
 	}
@@ -42,67 +65,177 @@ func (h *InventoryHandler) GetInventory(c *gin.Context) {
 
 // AdjustStock updates the inventory quantity for a specific product
 func (h *InventoryHandler) AdjustStock(c *gin.Context) {
-	productID := c.Param("product_id")
-	location := c.DefaultQuery("location", "Warehouse A") // Default to Warehouse A if not specified
-	
-	var adjustment StockAdjustment
-	if err := c.ShouldBindJSON(&adjustment); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	productID, err := strconv.ParseUint(c.Param("product_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
 
-	// Check if product exists
-	var product models.Product
-	if result := h.DB.First(&product, productID); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	var input StockAdjustment
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Find or create inventory entry
-	var inventory models.Inventory
-	result := h.DB.Where("product_id = ? AND location = ?", productID, location).First(&inventory)
-	
-	if result.Error != nil {
-		// Create new inventory entry if it doesn't exist
-		inventory = models.Inventory{
-			ProductID: product.ID,
-			Location:  location,
-			Quantity:  0,
-		}
+	request := services.AdjustmentRequest{
+		ProductID: uint(productID),
+		Location:  c.Query("location"), // Defaults to Warehouse A if not specified
+		Action:    input.Action,
+		Quantity:  input.Value,
+		Reason:    input.Reason,
+		Note:      input.Note,
+		UnitCost:  input.UnitCost,
 	}
 
-	// Adjust stock based on action
-	if adjustment.Action == "add" {
-		inventory.Quantity += adjustment.Value
-	} else { // "remove"
-		if inventory.Quantity < adjustment.Value {
-			c.JSON(http.StatusConflict, gin.H{"error": "Insufficient stock"})
+	// An explicit bin can be targeted, otherwise additions land in receiving
+	// and removals are picked across the location
+	if value := c.Query("bin_id"); value != "" {
+		binID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bin not found at this location"})
 			return
 		}
-		inventory.Quantity -= adjustment.Value
+		id := uint(binID)
+		request.BinID = &id
 	}
 
-	// Save changes
-	if result.Error != nil {
-		// Create new record
-		if createResult := h.DB.Create(&inventory); createResult.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory"})
-			return
-		}
-	} else {
-		// Update existing record
-		if updateResult := h.DB.Save(&inventory); updateResult.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory"})
-			return
+	location := request.Location
+	if location == "" {
+		location = services.DefaultLocation
+	}
+	if !allowLocation(c, location) {
+		return
+	}
+
+	result, err := h.Inventory.Adjust(c.Request.Context(), request)
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	case errors.Is(err, services.ErrBinNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bin not found at this location"})
+	case errors.Is(err, services.ErrUnknownLocation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown location"})
+	case errors.Is(err, services.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": "Insufficient stock"})
+	case err != nil && result.Pending:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue adjustment"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory"})
+	case result.Pending:
+		// Large adjustments wait in the approval queue instead of applying immediately
+		c.JSON(http.StatusAccepted, result.Adjustment)
+	default:
+		c.JSON(http.StatusOK, result.Inventory)
+	}
+}
+
+// GetAdjustments retrieves manual adjustments with optional status filtering
+func (h *InventoryHandler) GetAdjustments(c *gin.Context) {
+	filter := services.AdjustmentFilter{Status: c.Query("status")}
+	if value := c.Query("product_id"); value != "" {
+		productID, _ := strconv.ParseUint(value, 10, 32)
+		filter.ProductID = uint(productID)
+	}
+
+	adjustments, err := h.Inventory.ListAdjustments(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve adjustments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustments)
+}
+
+// ApproveAdjustment applies a pending adjustment
+func (h *InventoryHandler) ApproveAdjustment(c *gin.Context) {
+	id, decision, ok := bindDecision(c)
+	if !ok || !h.allowAdjustment(c, id) {
+		return
+	}
+
+	result, err := h.Inventory.ApproveAdjustment(c.Request.Context(), id, decision.Note)
+	if err != nil {
+		respondDecisionError(c, result.Adjustment, err, "Failed to apply adjustment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"adjustment": result.Adjustment, "inventory": result.Inventory})
+}
+
+// RejectAdjustment discards a pending adjustment without changing stock
+func (h *InventoryHandler) RejectAdjustment(c *gin.Context) {
+	id, decision, ok := bindDecision(c)
+	if !ok || !h.allowAdjustment(c, id) {
+		return
+	}
+
+	adjustment, err := h.Inventory.RejectAdjustment(c.Request.Context(), id, decision.Note)
+	if err != nil {
+		respondDecisionError(c, adjustment, err, "Failed to reject adjustment")
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// bindDecision reads the adjustment ID from the route and the optional decision note
+func bindDecision(c *gin.Context) (uint, AdjustmentDecision, bool) {
+	var decision AdjustmentDecision
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Adjustment not found"})
+		return 0, decision, false
+	}
+
+	// The decision note is optional, so an empty body is accepted
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&decision); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return 0, decision, false
 		}
 	}
 
-	c.JSON(http.StatusOK, inventory)
+	return uint(id), decision, true
+}
+
+// allowAdjustment checks that the signed-in user may decide on an adjustment
+// at its location
+func (h *InventoryHandler) allowAdjustment(c *gin.Context, id uint) bool {
+	adjustment, err := h.Inventory.GetAdjustment(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, services.ErrAdjustmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Adjustment not found"})
+		return false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch adjustment"})
+		return false
+	}
+	return allowLocation(c, adjustment.Location)
+}
+
+// respondDecisionError maps a failed approval decision to its response
+func respondDecisionError(c *gin.Context, adjustment models.Adjustment, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAdjustmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Adjustment not found"})
+	case errors.Is(err, services.ErrAdjustmentNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "Adjustment is " + adjustment.Status})
+	case errors.Is(err, services.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": "Insufficient stock"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
 }
 
 // GetInventoryByLocation groups inventory by warehouse/location
 func (h *InventoryHandler) GetInventoryByLocation(c *gin.Context) {
-	results, err := database.GetStockDistributionByLocation(h.DB)
+	asOf, err := optionalAsOf(c, h.location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := database.GetStockDistributionByLocation(h.DB.WithContext(c.Request.Context()), asOf)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve inventory by location"})
 		return
@@ -118,11 +251,70 @@ func (h *InventoryHandler) GetLowStockProducts(c *gin.Context) {
 		threshold = 20
 	}
 
-	results, err := database.GetLowStockProducts(h.DB, threshold)
+	results, err := database.GetLowStockProducts(h.DB.WithContext(c.Request.Context()), threshold)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve low stock products"})
 		return
 	}
 
 	c.JSON(http.StatusOK, results)
-}
\ No newline at end of file
+}
+
+// GetMovements retrieves the stock movement ledger with optional filtering
+func (h *InventoryHandler) GetMovements(c *gin.Context) {
+	var productID, countSessionID uint64
+	if value := c.Query("product_id"); value != "" {
+		productID, _ = strconv.ParseUint(value, 10, 32)
+	}
+	if value := c.Query("count_session_id"); value != "" {
+		countSessionID, _ = strconv.ParseUint(value, 10, 32)
+	}
+
+	movements, err := database.GetMovements(h.DB.WithContext(c.Request.Context()), uint(productID), c.Query("location"), uint(countSessionID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stock movements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, movements)
+}
+
+// GetValuation values the stock on hand under the configured costing method,
+// optionally as of a past date
+func (h *InventoryHandler) GetValuation(c *gin.Context) {
+	asOf := time.Now()
+	if value := c.Query("as_of"); value != "" {
+		parsed, err := parseAsOf(value, h.location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid as_of date, use YYYY-MM-DD or RFC3339"})
+			return
+		}
+		asOf = parsed
+	}
+
+	results, err := database.GetInventoryValuation(h.DB.WithContext(c.Request.Context()), asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve inventory valuation"})
+		return
+	}
+
+	total := 0.0
+	for _, row := range results {
+		total += row.TotalValue
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"method":      database.CostingMethod,
+		"as_of":       asOf,
+		"items":       results,
+		"total_value": total,
+	})
+}
+
+// location returns the configured time zone, defaulting to local time
+func (h *InventoryHandler) location() *time.Location {
+	if h.Location == nil {
+		return time.Local
+	}
+	return h.Location
+}
@@ -2,9 +2,11 @@
 package handlers
 
 import (
+	"errors"
 	"inventory_system/database"
-	"inventory_system/models"
+	"inventory_system/services"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,20 +14,21 @@ import (
 )
 
 type OrderHandler struct {
-	DB *gorm.DB
+	// DB is used for the revenue and margin reports
+	DB     *gorm.DB
+	Orders services.OrderService
 }
 
 type CreateOrderInput struct {
-	ProductID uint `json:"product_id" binding:"required"`
-	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+	ProductID uint   `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,gt=0"`
+	Location  string `json:"location"`
 }
 
 // GetOrders retrieves all orders
 func (h *OrderHandler) GetOrders(c *gin.Context) {
-	var orders []models.Order
-	
-	result := h.DB.Preload("Product").Find(&orders)
-	if result.Error != nil {
+	orders, err := h.Orders.List(c.Request.Context())
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve orders"})
 		return
 	}
@@ -35,14 +38,21 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 
 // GetOrder retrieves a single order by ID
 func (h *OrderHandler) GetOrder(c *gin.Context) {
-	id := c.Param("id")
-	var order models.Order
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
 
-	result := h.DB.Preload("Product").First(&order, id)
-	if result.Error != nil {
+	order, err := h.Orders.Get(c.Request.Context(), uint(id))
+	if errors.Is(err, services.ErrOrderNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order"})
+		return
+	}
 
 	c.JSON(http.StatusOK, order)
 }
@@ -55,70 +65,96 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	// Check if product exists
-	var product models.Product
-	if result := h.DB.First(&product, input.ProductID); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	location := input.Location
+	if location == "" {
+		location = services.DefaultLocation
+	}
+	if !allowLocation(c, location) {
 		return
 	}
 
-	// Check if there's enough inventory (using first warehouse as default)
-	var inventory models.Inventory
-	result := h.DB.Where("product_id = ? AND location = ?", input.ProductID, "Warehouse A").First(&inventory)
-	if result.Error != nil || inventory.Quantity < input.Quantity {
+	order, err := h.Orders.Create(c.Request.Context(), services.OrderRequest{
+		ProductID: input.ProductID,
+		Quantity:  input.Quantity,
+		Location:  input.Location,
+	})
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	case errors.Is(err, services.ErrUnknownLocation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown location"})
+		return
+	case errors.Is(err, services.ErrInsufficientStock):
 		c.JSON(http.StatusConflict, gin.H{"error": "Insufficient stock"})
 		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+		return
 	}
 
-	// Begin transaction
-	tx := h.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
+	c.JSON(http.StatusCreated, order)
+}
 
-	// Create order
-	order := models.Order{
-		ProductID:  input.ProductID,
-		Quantity:   input.Quantity,
-		OrderDate:  time.Now(),
-		TotalPrice: product.Price * float64(input.Quantity),
+// GetRevenueByCategory gets revenue statistics grouped by product category
+func (h *OrderHandler) GetRevenueByCategory(c *gin.Context) {
+	from, to, err := parseDateRange(c, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := tx.Create(&order).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+	results, err := database.GetRevenueByCategory(h.DB.WithContext(c.Request.Context()), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve revenue data"})
 		return
 	}
 
-	// Update inventory
-	inventory.Quantity -= input.Quantity
-	if err := tx.Save(&inventory).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory"})
+	c.JSON(http.StatusOK, results)
+}
+
+// GetGrossMargin gets revenue, cost of goods sold and gross margin grouped by
+// product, category, location or period
+func (h *OrderHandler) GetGrossMargin(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", "category")
+	if !database.IsValidMarginGroup(groupBy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be one of product, category, location or period"})
 		return
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+	from, to, err := parseDateRange(c, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Fetch the complete order with product details
-	h.DB.Preload("Product").First(&order, order.OrderID)
+	results, err := database.GetGrossMargin(h.DB.WithContext(c.Request.Context()), groupBy, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve margin data"})
+		return
+	}
 
-	c.JSON(http.StatusCreated, order)
+	c.JSON(http.StatusOK, results)
 }
 
-// GetRevenueByCategory gets revenue statistics grouped by product category
-func (h *OrderHandler) GetRevenueByCategory(c *gin.Context) {
-	results, err := database.GetRevenueByCategory(h.DB)
+// GetProductsByMargin ranks products by their contribution to gross margin
+func (h *OrderHandler) GetProductsByMargin(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	from, to, err := parseDateRange(c, time.Local)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve revenue data"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := database.GetProductsByMargin(h.DB.WithContext(c.Request.Context()), limit, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve margin data"})
 		return
 	}
 
 	c.JSON(http.StatusOK, results)
-}
\ No newline at end of file
+}
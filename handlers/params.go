@@ -0,0 +1,87 @@
+// handlers/params.go
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseAsOf parses a date as the end of that day in loc, or an exact RFC3339 timestamp
+func parseAsOf(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation(dateLayout, value, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// optionalAsOf reads the optional as_of query parameter, taking dates as days
+// in loc; nil means now
+func optionalAsOf(c *gin.Context, loc *time.Location) (*time.Time, error) {
+	value := c.Query("as_of")
+	if value == "" {
+		return nil, nil
+	}
+	asOf, err := parseAsOf(value, loc)
+	if err != nil {
+		return nil, errors.New("invalid as_of date, use YYYY-MM-DD or RFC3339")
+	}
+	return &asOf, nil
+}
+
+// parseDateRange reads the optional from and to query parameters as whole days in
+// loc; the returned end is exclusive so that the to day is included in full.
+func parseDateRange(c *gin.Context, loc *time.Location) (from, to *time.Time, err error) {
+	if value := c.Query("from"); value != "" {
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return nil, nil, errors.New("invalid from date, use YYYY-MM-DD")
+		}
+		from = &t
+	}
+	if value := c.Query("to"); value != "" {
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return nil, nil, errors.New("invalid to date, use YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("from must not be after to")
+	}
+	return from, to, nil
+}
+
+// positiveQuery reads a positive integer query parameter, using fallback when it
+// is missing or invalid
+func positiveQuery(c *gin.Context, name string, fallback int) int {
+	value, err := strconv.Atoi(c.Query(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// parseHorizon parses a forecast horizon such as 30d, 4w or 30 into days
+func parseHorizon(value string) (int, error) {
+	unit := 1
+	switch {
+	case strings.HasSuffix(value, "d"):
+		value = strings.TrimSuffix(value, "d")
+	case strings.HasSuffix(value, "w"):
+		value = strings.TrimSuffix(value, "w")
+		unit = 7
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		return 0, errors.New("invalid horizon, use a number of days such as 30d or weeks such as 4w")
+	}
+	return days * unit, nil
+}
@@ -2,57 +2,67 @@
 package handlers
 
 import (
+	"errors"
+	"inventory_system/database"
 	"inventory_system/models"
+	"inventory_system/services"
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type ProductHandler struct {
-	DB *gorm.DB
+	// DB is used for classification, which runs over the whole catalogue
+	DB       *gorm.DB
+	Products services.ProductService
+	// UploadDir is the directory product images are saved in
+	UploadDir string
+	// Location is the time zone demand weeks are bucketed in
+	Location *time.Location
 }
 
 type CreateProductInput struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gte=0"`
-	Category    string  `json:"category" binding:"required"`
+	Name         string  `json:"name" binding:"required"`
+	Description  string  `json:"description"`
+	Price        float64 `json:"price" binding:"required,gte=0"`
+	Category     string  `json:"category" binding:"required"`
+	StandardCost float64 `json:"standard_cost" binding:"omitempty,gte=0"`
 }
 
 type UpdateProductInput struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"omitempty,gte=0"`
-	Category    string  `json:"category"`
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Price        float64 `json:"price" binding:"omitempty,gte=0"`
+	Category     string  `json:"category"`
+	StandardCost float64 `json:"standard_cost" binding:"omitempty,gte=0"`
 }
 
 // GetProducts retrieves all products with optional filtering
 func (h *ProductHandler) GetProducts(c *gin.Context) {
-	var products []models.Product
-	db := h.DB
-
-	// Apply filters if provided
-	if category := c.Query("category"); category != "" {
-		db = db.Where("category = ?", category)
+	filter := services.ProductFilter{
+		Category: c.Query("category"),
+		AbcClass: c.Query("abc_class"),
+		XyzClass: c.Query("xyz_class"),
 	}
 
 	if minPrice := c.Query("min_price"); minPrice != "" {
 		if price, err := strconv.ParseFloat(minPrice, 64); err == nil {
-			db = db.Where("price >= ?", price)
+			filter.MinPrice = &price
 		}
 	}
 
 	if maxPrice := c.Query("max_price"); maxPrice != "" {
 		if price, err := strconv.ParseFloat(maxPrice, 64); err == nil {
-			db = db.Where("price <= ?", price)
+			filter.MaxPrice = &price
 		}
 	}
 
-	result := db.Find(&products)
-	if result.Error != nil {
+	products, err := h.Products.List(c.Request.Context(), filter)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve products"})
 		return
 	}
@@ -62,12 +72,8 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 
 // GetProduct retrieves a single product by ID
 func (h *ProductHandler) GetProduct(c *gin.Context) {
-	id := c.Param("id")
-	var product models.Product
-
-	result := h.DB.First(&product, id)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	product, ok := h.findProduct(c)
+	if !ok {
 		return
 	}
 
@@ -82,21 +88,20 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	// Validate category
-	if !isValidCategory(input.Category) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
-		return
-	}
-
 	product := models.Product{
-		Name:        input.Name,
-		Description: input.Description,
-		Price:       input.Price,
-		Category:    input.Category,
+		Name:         input.Name,
+		Description:  input.Description,
+		Price:        input.Price,
+		Category:     input.Category,
+		StandardCost: input.StandardCost,
 	}
 
-	result := h.DB.Create(&product)
-	if result.Error != nil {
+	err := h.Products.Create(c.Request.Context(), &product)
+	if errors.Is(err, services.ErrInvalidCategory) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
 		return
 	}
@@ -106,11 +111,8 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 // UpdateProduct updates an existing product
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
-	id := c.Param("id")
-	var product models.Product
-	
-	if result := h.DB.First(&product, id); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	id, ok := productID(c)
+	if !ok {
 		return
 	}
 
@@ -120,52 +122,48 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	// Apply updates only for fields that were provided
-	updates := make(map[string]interface{})
-	
-	if input.Name != "" {
-		updates["name"] = input.Name
-	}
-	
-	if input.Description != "" {
-		updates["description"] = input.Description
-	}
-	
-	if input.Price != 0 {
-		updates["price"] = input.Price
-	}
-	
-	if input.Category != "" {
-		if !isValidCategory(input.Category) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
-			return
-		}
-		updates["category"] = input.Category
+	product, err := h.Products.Update(c.Request.Context(), id, services.ProductUpdate{
+		Name:         input.Name,
+		Description:  input.Description,
+		Price:        input.Price,
+		Category:     input.Category,
+		StandardCost: input.StandardCost,
+	})
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	case errors.Is(err, services.ErrInvalidCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+		return
 	}
 
-	// Apply updates
-	if len(updates) > 0 {
-		result := h.DB.Model(&product).Updates(updates)
-		if result.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
-			return
-		}
+	c.JSON(http.StatusOK, product)
+}
+
+// ClassifyProducts runs ABC/XYZ classification over an optional window of days
+func (h *ProductHandler) ClassifyProducts(c *gin.Context) {
+	windowDays := positiveQuery(c, "window_days", 365)
+
+	result, err := database.ClassifyProducts(h.DB.WithContext(c.Request.Context()), windowDays, time.Now(), h.location())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to classify products"})
+		return
 	}
 
-	// Get updated product
-	h.DB.First(&product, id)
-	c.JSON(http.StatusOK, product)
+	c.JSON(http.StatusOK, result)
 }
 
 // UploadProductImage handles file uploads for product images
 func (h *ProductHandler) UploadProductImage(c *gin.Context) {
-	id := c.Param("id")
-	var product models.Product
-	
-	if result := h.DB.First(&product, id); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	product, ok := h.findProduct(c)
+	if !ok {
 		return
 	}
+	id := c.Param("id")
 
 	file, err := c.FormFile("image")
 	if err != nil {
@@ -182,7 +180,7 @@ func (h *ProductHandler) UploadProductImage(c *gin.Context) {
 
 	// Create unique filename
 	filename := "product_" + id + ext
-	filepath := filepath.Join("uploads", filename)
+	filepath := filepath.Join(h.UploadDir, filename)
 
 	// Save file
 	if err := c.SaveUploadedFile(file, filepath); err != nil {
@@ -191,22 +189,22 @@ func (h *ProductHandler) UploadProductImage(c *gin.Context) {
 	}
 
 	// Update product with image path
-	h.DB.Model(&product).Update("image_path", filepath)
+	if err := h.Products.SetImage(c.Request.Context(), product.ID, filepath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "File uploaded successfully",
-		"filepath": filepath,
+		"message":    "File uploaded successfully",
+		"filepath":   filepath,
 		"product_id": id,
 	})
 }
 
 // GetProductImage serves the product image
 func (h *ProductHandler) GetProductImage(c *gin.Context) {
-	id := c.Param("id")
-	var product models.Product
-	
-	if result := h.DB.First(&product, id); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	product, ok := h.findProduct(c)
+	if !ok {
 		return
 	}
 
@@ -218,13 +216,39 @@ func (h *ProductHandler) GetProductImage(c *gin.Context) {
 	c.File(product.ImagePath)
 }
 
-// Utility function to validate product category
-func isValidCategory(category string) bool {
-	validCategories := []string{"Electronics", "Apparel", "Footwear", "Furniture", "Appliances"}
-	for _, c := range validCategories {
-		if category == c {
-			return true
-		}
+// findProduct loads the product named by the id route parameter
+func (h *ProductHandler) findProduct(c *gin.Context) (models.Product, bool) {
+	id, ok := productID(c)
+	if !ok {
+		return models.Product{}, false
 	}
-	return false
-}
\ No newline at end of file
+
+	product, err := h.Products.Get(c.Request.Context(), id)
+	if errors.Is(err, services.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return product, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve product"})
+		return product, false
+	}
+	return product, true
+}
+
+// productID reads the id route parameter; a malformed ID cannot name a product
+func productID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// location returns the configured time zone, defaulting to local time
+func (h *ProductHandler) location() *time.Location {
+	if h.Location == nil {
+		return time.Local
+	}
+	return h.Location
+}
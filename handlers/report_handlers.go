@@ -0,0 +1,226 @@
+// handlers/report_handlers.go
+package handlers
+
+import (
+	"inventory_system/database"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReportHandler struct {
+	DB *gorm.DB
+	// Location is the time zone reports are bucketed in
+	Location *time.Location
+}
+
+// GetSales returns zero-filled sales time series for charting
+func (h *ReportHandler) GetSales(c *gin.Context) {
+	loc := h.location()
+
+	granularity := c.DefaultQuery("granularity", database.GranularityDay)
+	if !database.IsValidGranularity(granularity) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be one of day, week or month"})
+		return
+	}
+
+	groupBy := c.Query("group_by")
+	if !database.IsValidSalesGroup(groupBy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be one of category, product or location"})
+		return
+	}
+
+	from, to, err := parseDateRange(c, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Default to the last 30 days, today included
+	if to == nil {
+		end := database.BucketStart(time.Now(), database.GranularityDay, loc).AddDate(0, 0, 1)
+		to = &end
+	}
+	if from == nil {
+		start := to.AddDate(0, 0, -30)
+		from = &start
+	}
+
+	report, err := database.GetSalesSeries(h.DB.WithContext(c.Request.Context()), *from, *to, granularity, groupBy, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTopSellers ranks products by units sold within an optional date range
+func (h *ReportHandler) GetTopSellers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	from, to, err := parseDateRange(c, h.location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := database.GetTopSellingProducts(h.DB.WithContext(c.Request.Context()), limit, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve top sellers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetRevenue gets revenue per category within an optional date range
+func (h *ReportHandler) GetRevenue(c *gin.Context) {
+	from, to, err := parseDateRange(c, h.location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := database.GetRevenueByCategory(h.DB.WithContext(c.Request.Context()), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve revenue data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetInventoryValue gets the value of stock at cost per category, as of now or
+// as of the end of the optional as_of day
+func (h *ReportHandler) GetInventoryValue(c *gin.Context) {
+	asOf := time.Now()
+	if value := c.Query("as_of"); value != "" {
+		parsed, err := parseAsOf(value, h.location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid as_of date, use YYYY-MM-DD or RFC3339"})
+			return
+		}
+		asOf = parsed
+	}
+
+	results, err := database.GetInventoryValueByCategory(h.DB.WithContext(c.Request.Context()), asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve inventory value"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetStockDistribution gets the total stock held at each location, optionally as of a past date
+func (h *ReportHandler) GetStockDistribution(c *gin.Context) {
+	asOf, err := optionalAsOf(c, h.location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := database.GetStockDistributionByLocation(h.DB.WithContext(c.Request.Context()), asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stock distribution"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetLowStock gets products whose stock at a location is below the threshold
+func (h *ReportHandler) GetLowStock(c *gin.Context) {
+	threshold, err := strconv.Atoi(c.DefaultQuery("threshold", "20"))
+	if err != nil {
+		threshold = 20
+	}
+
+	results, err := database.GetLowStockProducts(h.DB.WithContext(c.Request.Context()), threshold)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve low stock products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetTurnover gets inventory turnover per product and location over the last days
+func (h *ReportHandler) GetTurnover(c *gin.Context) {
+	days := positiveQuery(c, "days", 90)
+
+	results, err := database.GetStockAnalytics(h.DB.WithContext(c.Request.Context()), days, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute inventory turnover"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetDaysOfSupply gets how long the stock of each product at each location lasts
+// at the sales velocity of the last days, shortest supply first
+func (h *ReportHandler) GetDaysOfSupply(c *gin.Context) {
+	days := positiveQuery(c, "days", 30)
+
+	results, err := database.GetStockAnalytics(h.DB.WithContext(c.Request.Context()), days, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute days of supply"})
+		return
+	}
+
+	// Items with an optional maximum supply only; items without sales never run out
+	if value := c.Query("max_days"); value != "" {
+		maxDays, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_days"})
+			return
+		}
+		filtered := results[:0]
+		for _, row := range results {
+			if row.DaysOfSupply != nil && *row.DaysOfSupply <= maxDays {
+				filtered = append(filtered, row)
+			}
+		}
+		results = filtered
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].DaysOfSupply, results[j].DaysOfSupply
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a < *b
+	})
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetDeadStock gets items holding stock with no sales in the last days
+func (h *ReportHandler) GetDeadStock(c *gin.Context) {
+	days := positiveQuery(c, "days", 90)
+
+	results, err := database.GetDeadStock(h.DB.WithContext(c.Request.Context()), days, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dead stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// location returns the reporting time zone
+func (h *ReportHandler) location() *time.Location {
+	if h.Location == nil {
+		return time.Local
+	}
+	return h.Location
+}
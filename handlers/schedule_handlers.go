@@ -0,0 +1,189 @@
+// handlers/schedule_handlers.go
+package handlers
+
+import (
+	"inventory_system/models"
+	"inventory_system/reports"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ScheduleHandler struct {
+	DB *gorm.DB
+	// Sinks configures where scheduled reports can be delivered
+	Sinks reports.SinkConfig
+	// Location is the time zone cron expressions and report dates are read in
+	Location *time.Location
+}
+
+type ReportScheduleInput struct {
+	Name   string            `json:"name" binding:"required"`
+	Report string            `json:"report" binding:"required"`
+	Params map[string]string `json:"params"`
+	Cron   string            `json:"cron" binding:"required"`
+	Format string            `json:"format" binding:"required,oneof=csv html pdf"`
+	Sink   string            `json:"sink" binding:"required,oneof=file smtp webhook"`
+	Target string            `json:"target"`
+	Active *bool             `json:"active"`
+}
+
+// GetSchedules lists the report schedules
+func (h *ScheduleHandler) GetSchedules(c *gin.Context) {
+	var schedules []models.ReportSchedule
+	if err := h.DB.WithContext(c.Request.Context()).Order("id").Find(&schedules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve report schedules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// GetSchedule returns a single report schedule
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	var schedule models.ReportSchedule
+	if err := h.DB.WithContext(c.Request.Context()).First(&schedule, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// CreateSchedule adds a report schedule
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var schedule models.ReportSchedule
+	if !h.bindSchedule(c, &schedule) {
+		return
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Create(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create report schedule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// UpdateSchedule replaces the settings of a report schedule
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	var schedule models.ReportSchedule
+	if err := h.DB.WithContext(c.Request.Context()).First(&schedule, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report schedule not found"})
+		return
+	}
+
+	if !h.bindSchedule(c, &schedule) {
+		return
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Save(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update report schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule removes a report schedule; its delivery history is kept
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	result := h.DB.WithContext(c.Request.Context()).Delete(&models.ReportSchedule{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete report schedule"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report schedule deleted"})
+}
+
+// RunSchedule renders and delivers a report immediately, outside its schedule
+func (h *ScheduleHandler) RunSchedule(c *gin.Context) {
+	var schedule models.ReportSchedule
+	if err := h.DB.WithContext(c.Request.Context()).First(&schedule, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report schedule not found"})
+		return
+	}
+
+	delivery, err := reports.Run(c.Request.Context(), h.DB.WithContext(c.Request.Context()), h.Sinks, schedule, time.Now(), h.location())
+	if err != nil && delivery.ID == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record report delivery"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, delivery)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// GetDeliveries returns the delivery history, newest first, optionally for one
+// schedule or with one status
+func (h *ScheduleHandler) GetDeliveries(c *gin.Context) {
+	var deliveries []models.ReportDelivery
+	db := h.DB.WithContext(c.Request.Context())
+
+	if id := c.Param("id"); id != "" {
+		db = db.Where("schedule_id = ?", id)
+	}
+	if status := c.Query("status"); status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if err := db.Order("started_at DESC, id DESC").Limit(positiveQuery(c, "limit", 100)).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve report deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// bindSchedule validates the request body and copies it onto schedule, working
+// out the next run time from the cron expression
+func (h *ScheduleHandler) bindSchedule(c *gin.Context, schedule *models.ReportSchedule) bool {
+	var input ReportScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if !reports.IsValidReport(input.Report) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "report must be one of " + strings.Join(reports.Names(), ", ")})
+		return false
+	}
+	if err := reports.ValidateTarget(input.Sink, input.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	next, err := reports.NextRun(input.Cron, time.Now(), h.location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cron expression: " + err.Error()})
+		return false
+	}
+
+	schedule.Name = input.Name
+	schedule.Report = input.Report
+	schedule.Params = input.Params
+	schedule.Cron = input.Cron
+	schedule.Format = input.Format
+	schedule.Sink = input.Sink
+	schedule.Target = input.Target
+	schedule.Active = input.Active == nil || *input.Active
+	schedule.NextRunAt = &next
+	return true
+}
+
+// location returns the configured time zone, defaulting to local time
+func (h *ScheduleHandler) location() *time.Location {
+	if h.Location == nil {
+		return time.Local
+	}
+	return h.Location
+}
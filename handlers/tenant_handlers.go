@@ -0,0 +1,59 @@
+// handlers/tenant_handlers.go
+package handlers
+
+import (
+	"errors"
+	"inventory_system/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	Tenants services.TenantService
+}
+
+type UpdateTenantInput struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories" binding:"required"`
+	Locations  []string `json:"locations"`
+}
+
+// GetTenant returns the signed-in user's tenant with its categories and locations
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	tenant, err := h.Tenants.Current(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tenant"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// UpdateTenant replaces the categories and locations of the signed-in user's tenant
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	var input UpdateTenantInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant, err := h.Tenants.Update(c.Request.Context(), services.TenantRequest{
+		Name:       input.Name,
+		Categories: input.Categories,
+		Locations:  input.Locations,
+	})
+	switch {
+	case errors.Is(err, services.ErrCategoriesRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrTenantNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tenant"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
@@ -0,0 +1,186 @@
+// handlers/webhook_handlers.go
+package handlers
+
+import (
+	"inventory_system/models"
+	"inventory_system/reports"
+	"inventory_system/webhooks"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	DB *gorm.DB
+}
+
+type WebhookInput struct {
+	URL               string   `json:"url" binding:"required"`
+	Events            []string `json:"events" binding:"required,min=1"`
+	LowStockThreshold *int     `json:"low_stock_threshold" binding:"omitempty,min=1"`
+	Active            *bool    `json:"active"`
+}
+
+type ReplayInput struct {
+	DeliveryIDs []uint `json:"delivery_ids"`
+}
+
+// GetWebhooks lists the webhooks
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	var hooks []models.Webhook
+	if err := h.DB.WithContext(c.Request.Context()).Order("id").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hooks)
+}
+
+// GetWebhook returns a single webhook
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	var webhook models.Webhook
+	if err := h.DB.WithContext(c.Request.Context()).First(&webhook, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// CreateWebhook subscribes a URL to events; the signing secret is only ever
+// returned in this response
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	webhook := models.Webhook{LowStockThreshold: 20}
+	if !h.bindWebhook(c, &webhook) {
+		return
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	webhook.Secret = secret
+
+	if err := h.DB.WithContext(c.Request.Context()).Create(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"webhook": webhook, "secret": secret})
+}
+
+// UpdateWebhook replaces the settings of a webhook; its secret is kept
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	var webhook models.Webhook
+	if err := h.DB.WithContext(c.Request.Context()).First(&webhook, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	if !h.bindWebhook(c, &webhook) {
+		return
+	}
+
+	if err := h.DB.WithContext(c.Request.Context()).Save(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook removes a webhook; its delivery log is kept
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	result := h.DB.WithContext(c.Request.Context()).Delete(&models.Webhook{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// GetDeliveries returns the delivery log of a webhook, newest first,
+// optionally with one status or for one event
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	var deliveries []models.WebhookDelivery
+	db := h.DB.WithContext(c.Request.Context()).Where("webhook_id = ?", c.Param("id"))
+
+	if status := c.Query("status"); status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if event := c.Query("event"); event != "" {
+		db = db.Where("event = ?", event)
+	}
+
+	if err := db.Order("id DESC").Limit(positiveQuery(c, "limit", 100)).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayDeliveries queues failed deliveries of a webhook to be sent again: the
+// ones listed in the body, or all of them when it lists none
+func (h *WebhookHandler) ReplayDeliveries(c *gin.Context) {
+	db := h.DB.WithContext(c.Request.Context())
+
+	var webhook models.Webhook
+	if err := db.First(&webhook, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	var input ReplayInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	replayed, err := webhooks.Replay(db, webhook.ID, input.DeliveryIDs, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"replayed": replayed})
+}
+
+// bindWebhook validates the request body and copies it onto webhook
+func (h *WebhookHandler) bindWebhook(c *gin.Context, webhook *models.Webhook) bool {
+	var input WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if err := reports.ValidateTarget(models.SinkWebhook, input.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an http or https URL"})
+		return false
+	}
+	for _, event := range input.Events {
+		if !webhooks.IsValidEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "events must be among " + strings.Join(webhooks.Events, ", ")})
+			return false
+		}
+	}
+
+	webhook.URL = input.URL
+	webhook.Events = input.Events
+	if input.LowStockThreshold != nil {
+		webhook.LowStockThreshold = *input.LowStockThreshold
+	}
+	webhook.Active = input.Active == nil || *input.Active
+	return true
+}
@@ -0,0 +1,42 @@
+// jobs/classification.go
+package jobs
+
+import (
+	"context"
+	"inventory_system/database"
+	"inventory_system/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StartClassification reclassifies each tenant's products every interval over
+// a window of windowDays, in weeks of the loc time zone, until ctx is cancelled
+func StartClassification(ctx context.Context, db *gorm.DB, loc *time.Location, interval time.Duration, windowDays int) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				err := database.ForEachTenant(db.WithContext(ctx), func(tenant models.Tenant, tx *gorm.DB) error {
+					// One tenant failing does not hold up the others
+					result, err := database.ClassifyProducts(tx, windowDays, now, loc)
+					if err != nil {
+						log.Printf("Product classification of %s failed: %v", tenant.Name, err)
+						return nil
+					}
+					log.Printf("Classified %d products of %s over %d days", len(result.Products), tenant.Name, windowDays)
+					return nil
+				})
+				if err != nil {
+					log.Printf("Product classification failed: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,30 @@
+// jobs/outbox.go
+package jobs
+
+import (
+	"context"
+	"inventory_system/events"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StartOutboxRelay publishes outbox events every interval until ctx is cancelled
+func StartOutboxRelay(ctx context.Context, db *gorm.DB, relay *events.Relay, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := relay.RunDue(ctx, db, now); err != nil {
+					log.Printf("Outbox relay failed: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,31 @@
+// jobs/reports.go
+package jobs
+
+import (
+	"context"
+	"inventory_system/reports"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StartReportScheduler checks for due report schedules every interval and
+// delivers them until ctx is cancelled
+func StartReportScheduler(ctx context.Context, db *gorm.DB, config reports.SinkConfig, loc *time.Location, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := reports.RunDue(ctx, db, config, now, loc); err != nil {
+					log.Printf("Report scheduler failed: %v", err)
+				}
+			}
+		}
+	}()
+}
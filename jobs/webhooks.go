@@ -0,0 +1,31 @@
+// jobs/webhooks.go
+package jobs
+
+import (
+	"context"
+	"inventory_system/webhooks"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StartWebhookDispatcher sends due webhook deliveries every interval until ctx
+// is cancelled
+func StartWebhookDispatcher(ctx context.Context, db *gorm.DB, dispatcher webhooks.Dispatcher, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := dispatcher.RunDue(ctx, db, now); err != nil {
+					log.Printf("Webhook dispatcher failed: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -2,32 +2,13 @@
 package main
 
 import (
-	"inventory_system/database"
-	"inventory_system/routes"
+	"inventory_system/cli"
 	"log"
 	"os"
 )
 
 func main() {
-	// Create uploads directory if it doesn't exist
-	if _, err := os.Stat("uploads"); os.IsNotExist(err) {
-		os.Mkdir("uploads", 0755)
+	if err := cli.Run(os.Args[1:]); err != nil {
+		log.Fatal(err)
 	}
-
-	// Initialize database
-	db := database.Initialize()
-
-	// Setup router
-	r := routes.SetupRouter(db)
-
-	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-	
-	log.Printf("Server starting on port %s...", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
-	}
-}
\ No newline at end of file
+}
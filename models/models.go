@@ -5,9 +5,23 @@ import (
 	"time"
 )
 
+// Tenant is a business sharing the deployment. Every other record belongs to
+// one tenant through its TenantID, which queries are scoped to automatically.
+type Tenant struct {
+	ID   uint   `json:"id" gorm:"primaryKey;size:32"`
+	Name string `json:"name" gorm:"size:100;not null;uniqueIndex"`
+	// Categories are the product categories the tenant may use
+	Categories []string `json:"categories" gorm:"serializer:json;type:text"`
+	// Locations are the tenant's stock locations; any location is accepted when empty
+	Locations []string  `json:"locations" gorm:"serializer:json;type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // Product represents an item that can be sold
 type Product struct {
-	ID          uint      `json:"id" gorm:"primaryKey;type:int unsigned"`
+	ID          uint      `json:"id" gorm:"primaryKey;size:32"`
+	TenantID    uint      `json:"-" gorm:"size:32;not null;index"`
 	Name        string    `json:"name" gorm:"size:100;not null"`
 	Description string    `json:"description" gorm:"type:text"`
 	Price       float64   `json:"price" gorm:"type:decimal(10,2);not null;check:price >= 0"`
@@ -15,11 +29,21 @@ type Product struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	ImagePath   string    `json:"image_path,omitempty" gorm:"size:255"`
+	// StandardCost is the fixed unit cost used by standard costing
+	StandardCost float64 `json:"standard_cost" gorm:"type:decimal(12,4);not null;default:0"`
+	// AverageCost is the moving weighted-average unit cost of the stock on hand
+	AverageCost float64 `json:"average_cost" gorm:"type:decimal(12,4);not null;default:0"`
+	// AbcClass ranks the product by revenue contribution (A, B or C)
+	AbcClass string `json:"abc_class,omitempty" gorm:"size:1;index"`
+	// XyzClass ranks the product by demand variability (X, Y or Z)
+	XyzClass     string     `json:"xyz_class,omitempty" gorm:"size:1;index"`
+	ClassifiedAt *time.Time `json:"classified_at,omitempty"`
 }
 
 // Inventory represents the stock of a product at a specific location
 type Inventory struct {
-	ProductID uint    `json:"product_id" gorm:"primaryKey;type:int unsigned"`
+	TenantID  uint    `json:"-" gorm:"size:32;not null;index"`
+	ProductID uint    `json:"product_id" gorm:"primaryKey;size:32"`
 	Product   Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
 	Quantity  int     `json:"quantity" gorm:"not null;default:0"`
 	Location  string  `json:"location" gorm:"size:100;not null;primaryKey"`
@@ -27,10 +51,339 @@ type Inventory struct {
 
 // Order represents a customer order for a specific product
 type Order struct {
-	OrderID    uint      `json:"order_id" gorm:"primaryKey;type:int unsigned"`
-	ProductID  uint      `json:"product_id" gorm:"type:int unsigned;not null"`
+	OrderID    uint      `json:"order_id" gorm:"primaryKey;size:32"`
+	TenantID   uint      `json:"-" gorm:"size:32;not null;index"`
+	ProductID  uint      `json:"product_id" gorm:"size:32;not null"`
 	Product    Product   `json:"product,omitempty" gorm:"foreignKey:ProductID"`
 	Quantity   int       `json:"quantity" gorm:"not null;check:quantity > 0"`
-	OrderDate  time.Time `json:"order_date" gorm:"not null"`
+	Location   string    `json:"location" gorm:"size:100;not null;default:'Warehouse A'"`
+	OrderDate  time.Time `json:"order_date" gorm:"not null;index:idx_orders_date"`
 	TotalPrice float64   `json:"total_price" gorm:"type:decimal(10,2);not null"`
-}
\ No newline at end of file
+	// CostOfGoods is the cost of the units sold under the configured costing method
+	CostOfGoods float64 `json:"cost_of_goods" gorm:"type:decimal(12,2);not null;default:0"`
+}
+
+// ReceivingBin is the code of the bin that holds stock not yet put away
+const ReceivingBin = "RECEIVING"
+
+// Bin represents a storage position (zone/aisle/shelf/bin) inside a location
+type Bin struct {
+	ID       uint   `json:"id" gorm:"primaryKey;size:32"`
+	TenantID uint   `json:"-" gorm:"size:32;not null;index;uniqueIndex:idx_bins_location_code,priority:1"`
+	Location string `json:"location" gorm:"size:100;not null;uniqueIndex:idx_bins_location_code"`
+	Zone     string `json:"zone" gorm:"size:20"`
+	Aisle    string `json:"aisle" gorm:"size:20"`
+	Shelf    string `json:"shelf" gorm:"size:20"`
+	Code     string `json:"code" gorm:"size:50;not null;uniqueIndex:idx_bins_location_code"`
+}
+
+// BinStock represents the stock of a product held in a specific bin
+type BinStock struct {
+	TenantID  uint    `json:"-" gorm:"size:32;not null;index"`
+	ProductID uint    `json:"product_id" gorm:"primaryKey;size:32"`
+	Product   Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	BinID     uint    `json:"bin_id" gorm:"primaryKey;size:32"`
+	Bin       Bin     `json:"bin,omitempty" gorm:"foreignKey:BinID"`
+	Quantity  int     `json:"quantity" gorm:"not null;default:0"`
+}
+
+// Stock movement types
+const (
+	MovementReceipt  = "receipt"
+	MovementIssue    = "issue"
+	MovementOrder    = "order"
+	MovementPutaway  = "putaway"
+	MovementTransfer = "transfer"
+	MovementCount    = "count"
+	MovementOpening  = "opening"
+)
+
+// StockMovement records a single change to the stock of a product at a location
+type StockMovement struct {
+	ID             uint      `json:"id" gorm:"primaryKey;size:32"`
+	TenantID       uint      `json:"-" gorm:"size:32;not null;index"`
+	ProductID      uint      `json:"product_id" gorm:"size:32;not null;index:idx_movements_product_location"`
+	Location       string    `json:"location" gorm:"size:100;not null;index:idx_movements_product_location"`
+	BinID          *uint     `json:"bin_id,omitempty" gorm:"size:32"`
+	Quantity       int       `json:"quantity" gorm:"not null"`
+	Type           string    `json:"type" gorm:"size:20;not null"`
+	Reason         string    `json:"reason,omitempty" gorm:"size:20"`
+	Reference      string    `json:"reference,omitempty" gorm:"size:100"`
+	CountSessionID *uint     `json:"count_session_id,omitempty" gorm:"size:32;index"`
+	UnitCost       float64   `json:"unit_cost" gorm:"type:decimal(12,4);not null;default:0"`
+	CostValue      float64   `json:"cost_value" gorm:"type:decimal(14,4);not null;default:0"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`
+}
+
+// Count session statuses
+const (
+	CountOpen      = "open"
+	CountApproved  = "approved"
+	CountCancelled = "cancelled"
+)
+
+// CountSession is a cycle count or stocktake of a location or a subset of products
+type CountSession struct {
+	ID        uint        `json:"id" gorm:"primaryKey;size:32"`
+	TenantID  uint        `json:"-" gorm:"size:32;not null;index"`
+	Location  string      `json:"location,omitempty" gorm:"size:100"`
+	Status    string      `json:"status" gorm:"size:20;not null;default:open;index"`
+	Note      string      `json:"note,omitempty" gorm:"type:text"`
+	CreatedAt time.Time   `json:"created_at"`
+	ClosedAt  *time.Time  `json:"closed_at,omitempty"`
+	Lines     []CountLine `json:"lines,omitempty" gorm:"foreignKey:SessionID"`
+}
+
+// CountLine holds the expected and counted quantity of one product at one location.
+// The expected quantity is never serialized so that staff count blind.
+type CountLine struct {
+	ID               uint       `json:"id" gorm:"primaryKey;size:32"`
+	TenantID         uint       `json:"-" gorm:"size:32;not null;index"`
+	SessionID        uint       `json:"session_id" gorm:"size:32;not null;index"`
+	ProductID        uint       `json:"product_id" gorm:"size:32;not null"`
+	Product          Product    `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Location         string     `json:"location" gorm:"size:100;not null"`
+	ExpectedQuantity int        `json:"-" gorm:"not null"`
+	CountedQuantity  *int       `json:"counted_quantity"`
+	CountedAt        *time.Time `json:"counted_at,omitempty"`
+}
+
+// Adjustment reason codes
+const (
+	ReasonDamage     = "damage"
+	ReasonTheft      = "theft"
+	ReasonFound      = "found"
+	ReasonCorrection = "correction"
+	ReasonSample     = "sample"
+	ReasonCount      = "count"
+)
+
+// Adjustment statuses
+const (
+	AdjustmentApplied  = "applied"
+	AdjustmentPending  = "pending"
+	AdjustmentApproved = "approved"
+	AdjustmentRejected = "rejected"
+)
+
+// Adjustment is a manual stock adjustment, either applied immediately or
+// held for approval when it exceeds the configured thresholds
+type Adjustment struct {
+	ID           uint       `json:"id" gorm:"primaryKey;size:32"`
+	TenantID     uint       `json:"-" gorm:"size:32;not null;index"`
+	ProductID    uint       `json:"product_id" gorm:"size:32;not null"`
+	Product      Product    `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Location     string     `json:"location" gorm:"size:100;not null"`
+	BinID        *uint      `json:"bin_id,omitempty" gorm:"size:32"`
+	Action       string     `json:"action" gorm:"size:10;not null"`
+	Quantity     int        `json:"quantity" gorm:"not null"`
+	UnitCost     *float64   `json:"unit_cost,omitempty" gorm:"type:decimal(12,4)"`
+	Value        float64    `json:"value" gorm:"type:decimal(12,2);not null"`
+	Reason       string     `json:"reason" gorm:"size:20;not null"`
+	Note         string     `json:"note,omitempty" gorm:"type:text"`
+	Status       string     `json:"status" gorm:"size:20;not null;index"`
+	DecisionNote string     `json:"decision_note,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+}
+
+// CostLayer is a receipt of stock at a unit cost, consumed oldest first by FIFO costing
+type CostLayer struct {
+	ID         uint      `json:"id" gorm:"primaryKey;size:32"`
+	TenantID   uint      `json:"-" gorm:"size:32;not null;index"`
+	ProductID  uint      `json:"product_id" gorm:"size:32;not null;index:idx_cost_layers_product_received"`
+	MovementID uint      `json:"movement_id" gorm:"size:32"`
+	Quantity   int       `json:"quantity" gorm:"not null"`
+	Remaining  int       `json:"remaining" gorm:"not null"`
+	UnitCost   float64   `json:"unit_cost" gorm:"type:decimal(12,4);not null"`
+	ReceivedAt time.Time `json:"received_at" gorm:"not null;index:idx_cost_layers_product_received"`
+}
+
+// Report output formats
+const (
+	ReportCSV  = "csv"
+	ReportHTML = "html"
+	ReportPDF  = "pdf"
+)
+
+// Report delivery sinks
+const (
+	SinkFile    = "file"
+	SinkSMTP    = "smtp"
+	SinkWebhook = "webhook"
+)
+
+// Report and webhook delivery statuses. Only webhook deliveries wait as pending.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// ReportSchedule renders a report on a cron schedule and delivers it to a sink
+type ReportSchedule struct {
+	ID       uint   `json:"id" gorm:"primaryKey;size:32"`
+	TenantID uint   `json:"-" gorm:"size:32;not null;index"`
+	Name     string `json:"name" gorm:"size:100;not null"`
+	Report   string `json:"report" gorm:"size:50;not null"`
+	// Params are the report query parameters, such as days or threshold
+	Params map[string]string `json:"params" gorm:"serializer:json;type:text"`
+	Cron   string            `json:"cron" gorm:"size:100;not null"`
+	Format string            `json:"format" gorm:"size:10;not null"`
+	Sink   string            `json:"sink" gorm:"size:20;not null"`
+	// Target is the directory, comma-separated email recipients or URL of the sink
+	Target    string     `json:"target" gorm:"size:500"`
+	Active    bool       `json:"active" gorm:"not null"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty" gorm:"index"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ReportDelivery records one attempt to render and deliver a scheduled report
+type ReportDelivery struct {
+	ID          uint      `json:"id" gorm:"primaryKey;size:32"`
+	TenantID    uint      `json:"-" gorm:"size:32;not null;index"`
+	ScheduleID  uint      `json:"schedule_id" gorm:"size:32;not null;index"`
+	Status      string    `json:"status" gorm:"size:20;not null"`
+	Format      string    `json:"format" gorm:"size:10;not null"`
+	Sink        string    `json:"sink" gorm:"size:20;not null"`
+	Destination string    `json:"destination,omitempty" gorm:"size:500"`
+	FileName    string    `json:"file_name" gorm:"size:255"`
+	Size        int       `json:"size"`
+	Rows        int       `json:"rows"`
+	Error       string    `json:"error,omitempty" gorm:"type:text"`
+	StartedAt   time.Time `json:"started_at" gorm:"index"`
+	FinishedAt  time.Time `json:"finished_at"`
+}
+
+// Webhook events
+const (
+	EventOrderCreated      = "order.created"
+	EventInventoryChanged  = "inventory.changed"
+	EventInventoryLowStock = "inventory.low_stock"
+	EventProductUpdated    = "product.updated"
+)
+
+// Webhook subscribes a URL to events. Deliveries are signed with the secret,
+// which is only returned when the webhook is created.
+type Webhook struct {
+	ID       uint     `json:"id" gorm:"primaryKey;size:32"`
+	TenantID uint     `json:"-" gorm:"size:32;not null;index"`
+	URL      string   `json:"url" gorm:"size:500;not null"`
+	Events   []string `json:"events" gorm:"serializer:json;type:text"`
+	Secret   string   `json:"-" gorm:"size:100;not null"`
+	// LowStockThreshold is the quantity below which inventory.low_stock fires
+	LowStockThreshold int       `json:"low_stock_threshold" gorm:"not null;default:20"`
+	Active            bool      `json:"active" gorm:"not null"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent, or still to be sent, to a webhook. Failed
+// attempts are retried until the delivery succeeds or runs out of attempts.
+type WebhookDelivery struct {
+	ID        uint `json:"id" gorm:"primaryKey;size:32"`
+	TenantID  uint `json:"-" gorm:"size:32;not null;index"`
+	WebhookID uint `json:"webhook_id" gorm:"size:32;not null;index"`
+	// EventID is the outbox event the delivery was queued for
+	EventID *uint  `json:"event_id,omitempty" gorm:"size:32;index"`
+	Event   string `json:"event" gorm:"size:50;not null"`
+	// Payload is the JSON body posted to the webhook
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"size:20;not null;index:idx_webhook_deliveries_status"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int        `json:"response_status,omitempty" gorm:"not null;default:0"`
+	Error          string     `json:"error,omitempty" gorm:"type:text"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" gorm:"index:idx_webhook_deliveries_status"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// OutboxEvent is an event recorded in the transaction of the change it
+// describes. The relay publishes the events of each aggregate in order and
+// sets PublishedAt once every sink has taken the event.
+type OutboxEvent struct {
+	ID       uint `json:"id" gorm:"primaryKey;size:32"`
+	TenantID uint `json:"tenant_id" gorm:"size:32;not null;index"`
+	// AggregateType and AggregateID name the record the event is about, as
+	// audit entries name their entity
+	AggregateType string `json:"aggregate_type" gorm:"size:20;not null"`
+	AggregateID   string `json:"aggregate_id" gorm:"size:150;not null"`
+	Event         string `json:"event" gorm:"size:50;not null"`
+	// Payload is the JSON data of the event
+	Payload       string     `json:"payload" gorm:"type:text;not null"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	Error         string     `json:"error,omitempty" gorm:"type:text"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	PublishedAt   *time.Time `json:"published_at,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// APIKey identifies a programmatic client. The key itself is shown once when
+// it is created; only its SHA-256 hash is stored.
+type APIKey struct {
+	ID       uint   `json:"id" gorm:"primaryKey;size:32"`
+	TenantID uint   `json:"-" gorm:"size:32;not null;index"`
+	Name     string `json:"name" gorm:"size:100;not null"`
+	Prefix   string `json:"prefix" gorm:"size:16;not null"`
+	KeyHash  string `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	// Scopes are the permissions the key grants, as for a user's role
+	Scopes []string `json:"scopes" gorm:"serializer:json;type:text"`
+	// Locations restricts the key's stock changes to these locations when not empty
+	Locations  []string   `json:"locations,omitempty" gorm:"serializer:json;type:text"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// User is a person who signs in to the API
+type User struct {
+	ID           uint   `json:"id" gorm:"primaryKey;size:32"`
+	TenantID     uint   `json:"-" gorm:"size:32;not null;index"`
+	Username     string `json:"username" gorm:"size:100;not null;uniqueIndex"`
+	PasswordHash string `json:"-" gorm:"size:100;not null"`
+	Role         string `json:"role" gorm:"size:50;not null"`
+	// Locations restricts the user's stock changes to these locations when not empty
+	Locations []string  `json:"locations,omitempty" gorm:"serializer:json;type:text"`
+	Active    bool      `json:"active" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Audit actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// Audited entities
+const (
+	EntityProduct   = "product"
+	EntityInventory = "inventory"
+	EntityOrder     = "order"
+)
+
+// AuditChange is the value of a field before and after a write; Before is nil
+// for creates and After for deletes
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEntry records who changed a product, inventory level or order, and how
+type AuditEntry struct {
+	ID       uint `json:"id" gorm:"primaryKey;size:32"`
+	TenantID uint `json:"-" gorm:"size:32;not null;index"`
+	// Actor is user:NAME, api_key:NAME, cli or system
+	Actor     string `json:"actor" gorm:"size:150;not null;index"`
+	RequestID string `json:"request_id,omitempty" gorm:"size:64;index"`
+	Entity    string `json:"entity" gorm:"size:20;not null;index:idx_audit_entries_entity"`
+	// EntityID is the product or order ID, or PRODUCT_ID:LOCATION for inventory
+	EntityID  string                 `json:"entity_id" gorm:"size:150;not null;index:idx_audit_entries_entity"`
+	Action    string                 `json:"action" gorm:"size:10;not null"`
+	Changes   map[string]AuditChange `json:"changes" gorm:"serializer:json;type:text"`
+	CreatedAt time.Time              `json:"created_at" gorm:"index"`
+}
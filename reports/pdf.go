@@ -0,0 +1,140 @@
+// reports/pdf.go
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDF page layout: landscape A4 in points, set in a monospaced font so that
+// columns line up without measuring text
+const (
+	pdfPageWidth  = 842
+	pdfPageHeight = 595
+	pdfMargin     = 36
+	pdfFontSize   = 8
+	pdfLeading    = 10
+	// Courier glyphs are 0.6em wide, so 160 characters fill the printable width
+	pdfLineChars = 160
+)
+
+// renderPDF lays the table out as fixed-width text over as many pages as needed
+func renderPDF(table Table) []byte {
+	lines := textTable(table)
+	perPage := (pdfPageHeight - 2*pdfMargin) / pdfLeading
+
+	var pages [][]string
+	for start := 0; start < len(lines); start += perPage {
+		end := start + perPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+		pages = append(pages, lines[start:end])
+	}
+
+	// Objects 1-3 are the catalog, page tree and font; each page then takes two
+	// objects, the page itself and its content stream
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	)
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range page {
+			if len(line) > pdfLineChars {
+				line = line[:pdfLineChars]
+			}
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// textTable formats the table as padded text lines under a title line
+func textTable(table Table) []string {
+	widths := make([]int, len(table.Columns))
+	for i, column := range table.Columns {
+		widths[i] = len(column)
+	}
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			if i < len(widths) && len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	format := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for i, cell := range cells {
+			padded[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		}
+		return strings.TrimRight(strings.Join(padded, "  "), " ")
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s - generated %s", table.Title, table.GeneratedAt.Format("2006-01-02 15:04 MST")),
+		"",
+		format(table.Columns),
+	}
+	separators := make([]string, len(widths))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width)
+	}
+	lines = append(lines, format(separators))
+	for _, row := range table.Rows {
+		lines = append(lines, format(row))
+	}
+	if len(table.Rows) == 0 {
+		lines = append(lines, "No rows")
+	}
+	return lines
+}
+
+// pdfEscape escapes a line for a PDF string literal, replacing characters the
+// standard Courier font cannot show
+func pdfEscape(line string) string {
+	var escaped strings.Builder
+	for _, r := range line {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < 32 || r > 126:
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,109 @@
+// reports/render.go
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Document is a rendered report ready for delivery
+type Document struct {
+	FileName    string
+	ContentType string
+	Body        []byte
+}
+
+// contentTypes maps each output format to its MIME type
+var contentTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"html": "text/html; charset=utf-8",
+	"pdf":  "application/pdf",
+}
+
+// IsValidFormat reports whether reports can be rendered in the given format
+func IsValidFormat(format string) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+// Render renders a table as CSV, HTML or PDF under a file name built from name
+// and the time the table was generated
+func Render(table Table, name, format string) (Document, error) {
+	document := Document{
+		FileName:    fmt.Sprintf("%s-%s.%s", fileSafe(name), table.GeneratedAt.Format("20060102-150405"), format),
+		ContentType: contentTypes[format],
+	}
+
+	var err error
+	switch format {
+	case "csv":
+		document.Body, err = renderCSV(table)
+	case "html":
+		document.Body, err = renderHTML(table)
+	case "pdf":
+		document.Body = renderPDF(table)
+	default:
+		err = fmt.Errorf("unknown report format %q", format)
+	}
+	return document, err
+}
+
+// renderCSV writes the header row followed by the table rows
+func renderCSV(table Table) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(table.Columns); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteAll(table.Rows); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// htmlReport is a standalone page with the report table
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f0f0f0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}</p>
+<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{else}}<tr><td colspan="{{len .Columns}}">No rows</td></tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+// renderHTML renders the table as a standalone HTML page
+func renderHTML(table Table) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := htmlReport.Execute(&buffer, table)
+	return buffer.Bytes(), err
+}
+
+// fileSafe replaces characters that are awkward in file names
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+}
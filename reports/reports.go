@@ -0,0 +1,241 @@
+// reports/reports.go
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"inventory_system/database"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Table is a rendered-ready report: a title, column headers and formatted cells
+type Table struct {
+	Title       string
+	GeneratedAt time.Time
+	Columns     []string
+	Rows        [][]string
+}
+
+// builder runs one reporting query with the schedule's parameters
+type builder func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error)
+
+// builders maps the report names accepted by schedules to their queries
+var builders = map[string]builder{
+	"sales": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		from, to, err := dateRange(params, now, loc)
+		if err != nil {
+			return nil, err
+		}
+		if from == nil {
+			start := daysBefore(now, loc, 30)
+			from = &start
+		}
+		if to == nil {
+			end := database.BucketStart(now, database.GranularityDay, loc)
+			to = &end
+		}
+		granularity := param(params, "granularity", database.GranularityDay)
+		if !database.IsValidGranularity(granularity) {
+			return nil, errors.New("granularity must be one of day, week or month")
+		}
+		report, err := database.GetSalesSeries(db, *from, *to, granularity, params["group_by"], loc)
+		if err != nil {
+			return nil, err
+		}
+		return salesRows(report), nil
+	},
+	"revenue": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		from, to, err := dateRange(params, now, loc)
+		if err != nil {
+			return nil, err
+		}
+		return database.GetRevenueByCategory(db, from, to)
+	},
+	"top-sellers": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		from, to, err := dateRange(params, now, loc)
+		if err != nil {
+			return nil, err
+		}
+		return database.GetTopSellingProducts(db, positive(params, "limit", 10), from, to)
+	},
+	"inventory-value": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		return database.GetInventoryValueByCategory(db, now)
+	},
+	"stock-distribution": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		return database.GetStockDistributionByLocation(db, nil)
+	},
+	"low-stock": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		return database.GetLowStockProducts(db, positive(params, "threshold", 20))
+	},
+	"turnover": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		return database.GetStockAnalytics(db, positive(params, "days", 90), now)
+	},
+	"days-of-supply": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		return database.GetStockAnalytics(db, positive(params, "days", 30), now)
+	},
+	"dead-stock": func(db *gorm.DB, params map[string]string, now time.Time, loc *time.Location) (interface{}, error) {
+		return database.GetDeadStock(db, positive(params, "days", 90), now)
+	},
+}
+
+// Names returns the reports that can be scheduled, sorted
+func Names() []string {
+	names := make([]string, 0, len(builders))
+	for name := range builders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsValidReport reports whether a report can be scheduled
+func IsValidReport(name string) bool {
+	_, ok := builders[name]
+	return ok
+}
+
+// Build runs a report and flattens its rows into a table
+func Build(db *gorm.DB, name string, params map[string]string, now time.Time, loc *time.Location) (Table, error) {
+	table := Table{Title: name, GeneratedAt: now.In(loc)}
+
+	build, ok := builders[name]
+	if !ok {
+		return table, fmt.Errorf("unknown report %q", name)
+	}
+	rows, err := build(db, params, now, loc)
+	if err != nil {
+		return table, err
+	}
+
+	table.Columns, table.Rows = flatten(rows)
+	return table, nil
+}
+
+// salesRow is one point of one sales series, flattened for tabular output
+type salesRow struct {
+	Group   string  `json:"group"`
+	Bucket  string  `json:"bucket"`
+	Orders  int     `json:"orders"`
+	Units   int     `json:"units"`
+	Revenue float64 `json:"revenue"`
+}
+
+// salesRows flattens a sales time series into one row per group and bucket
+func salesRows(report database.SalesReport) []salesRow {
+	rows := []salesRow{}
+	for _, series := range report.Series {
+		for _, point := range series.Points {
+			rows = append(rows, salesRow{series.Group, point.Bucket, point.Orders, point.Units, point.Revenue})
+		}
+	}
+	return rows
+}
+
+// flatten turns a slice of report row structs into column headers, taken from
+// their json tags, and formatted cells
+func flatten(rows interface{}) ([]string, [][]string) {
+	slice := reflect.ValueOf(rows)
+	if slice.Kind() != reflect.Slice {
+		return nil, nil
+	}
+
+	rowType := slice.Type().Elem()
+	var columns []string
+	var fields []int
+	for i := 0; i < rowType.NumField(); i++ {
+		name := strings.Split(rowType.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		columns = append(columns, name)
+		fields = append(fields, i)
+	}
+
+	cells := make([][]string, 0, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		row := make([]string, len(fields))
+		for j, field := range fields {
+			row[j] = formatCell(slice.Index(i).Field(field))
+		}
+		cells = append(cells, row)
+	}
+	return columns, cells
+}
+
+// formatCell formats one value of a report row; missing values are left blank
+func formatCell(value reflect.Value) string {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// dateRange reads the report window from the schedule's parameters: either the
+// last days whole days before now, or explicit from and to dates (to inclusive)
+func dateRange(params map[string]string, now time.Time, loc *time.Location) (from, to *time.Time, err error) {
+	if value, ok := params["days"]; ok {
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			return nil, nil, errors.New("days must be a positive number")
+		}
+		start := daysBefore(now, loc, days)
+		end := database.BucketStart(now, database.GranularityDay, loc)
+		return &start, &end, nil
+	}
+
+	if value := params["from"]; value != "" {
+		t, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return nil, nil, errors.New("invalid from date, use YYYY-MM-DD")
+		}
+		from = &t
+	}
+	if value := params["to"]; value != "" {
+		t, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return nil, nil, errors.New("invalid to date, use YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	return from, to, nil
+}
+
+// daysBefore returns the start of the day days before the day containing now
+func daysBefore(now time.Time, loc *time.Location, days int) time.Time {
+	return database.BucketStart(now, database.GranularityDay, loc).AddDate(0, 0, -days)
+}
+
+// param returns a parameter or a fallback when it is missing
+func param(params map[string]string, name, fallback string) string {
+	if v, ok := params[name]; ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+// positive returns a positive integer parameter or a fallback
+func positive(params map[string]string, name string, fallback int) int {
+	v, err := strconv.Atoi(params[name])
+	if err != nil || v <= 0 {
+		return fallback
+	}
+	return v
+}
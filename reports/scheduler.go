@@ -0,0 +1,108 @@
+// reports/scheduler.go
+package reports
+
+import (
+	"context"
+	"inventory_system/database"
+	"inventory_system/models"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+// NextRun returns the first time after after that a cron expression fires in loc.
+// Expressions use the standard five fields or descriptors such as @weekly.
+func NextRun(expression string, after time.Time, loc *time.Location) (time.Time, error) {
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(after.In(loc)), nil
+}
+
+// Run renders a schedule's report, delivers it to the schedule's sink and records
+// the attempt in the delivery history
+func Run(ctx context.Context, db *gorm.DB, config SinkConfig, schedule models.ReportSchedule, now time.Time, loc *time.Location) (models.ReportDelivery, error) {
+	delivery := models.ReportDelivery{
+		ScheduleID: schedule.ID,
+		Format:     schedule.Format,
+		Sink:       schedule.Sink,
+		StartedAt:  now,
+	}
+
+	err := deliver(ctx, db, config, schedule, now, loc, &delivery)
+	delivery.Status = models.DeliverySucceeded
+	if err != nil {
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = err.Error()
+	}
+	delivery.FinishedAt = time.Now()
+
+	if createErr := db.Create(&delivery).Error; createErr != nil {
+		return delivery, createErr
+	}
+	return delivery, err
+}
+
+// deliver builds, renders and sends one report, filling in the delivery details
+func deliver(ctx context.Context, db *gorm.DB, config SinkConfig, schedule models.ReportSchedule, now time.Time, loc *time.Location, delivery *models.ReportDelivery) error {
+	table, err := Build(db, schedule.Report, schedule.Params, now, loc)
+	if err != nil {
+		return err
+	}
+	table.Title = schedule.Name
+	delivery.Rows = len(table.Rows)
+
+	document, err := Render(table, schedule.Name, schedule.Format)
+	if err != nil {
+		return err
+	}
+	delivery.FileName = document.FileName
+	delivery.Size = len(document.Body)
+
+	sink, err := config.Sink(schedule.Sink)
+	if err != nil {
+		return err
+	}
+	delivery.Destination, err = sink.Deliver(ctx, schedule, document)
+	return err
+}
+
+// RunDue runs every active schedule whose next run time has passed and moves it
+// on to its following run time. Schedules of every tenant are run, each on its
+// own tenant's data. A schedule that fails is not retried until its next run;
+// the failure is kept in the delivery history.
+func RunDue(ctx context.Context, db *gorm.DB, config SinkConfig, now time.Time, loc *time.Location) error {
+	var schedules []models.ReportSchedule
+	if err := db.WithContext(database.AllTenants(ctx)).Where("active = ? AND next_run_at <= ?", true, now).Order("next_run_at").Find(&schedules).Error; err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		next, err := NextRun(schedule.Cron, now, loc)
+		if err != nil {
+			log.Printf("Report schedule %d has an invalid cron expression: %v", schedule.ID, err)
+			continue
+		}
+
+		tenantDB := db.WithContext(database.WithTenant(ctx, schedule.TenantID))
+
+		// Claim the run before delivering so that a slow delivery is not started twice
+		claimed := tenantDB.Model(&models.ReportSchedule{}).
+			Where("id = ? AND next_run_at = ?", schedule.ID, schedule.NextRunAt).
+			UpdateColumns(map[string]interface{}{"next_run_at": next, "last_run_at": now})
+		if claimed.Error != nil {
+			return claimed.Error
+		}
+		if claimed.RowsAffected == 0 {
+			continue
+		}
+
+		if _, err := Run(ctx, tenantDB, config, schedule, now, loc); err != nil {
+			log.Printf("Report schedule %d failed: %v", schedule.ID, err)
+		}
+	}
+	return nil
+}
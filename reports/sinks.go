@@ -0,0 +1,206 @@
+// reports/sinks.go
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"inventory_system/config"
+	"inventory_system/models"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sink delivers a rendered report and returns where it was delivered
+type Sink interface {
+	Deliver(ctx context.Context, schedule models.ReportSchedule, document Document) (string, error)
+}
+
+// SinkConfig holds the server-side settings of the delivery sinks
+type SinkConfig struct {
+	// OutboxDir is the directory file deliveries are written under
+	OutboxDir    string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// WebhookTimeout bounds each webhook delivery
+	WebhookTimeout time.Duration
+}
+
+// NewSinkConfig takes the sink settings from the service configuration.
+// Without SMTP credentials mail is sent unauthenticated, which suits a local
+// fake SMTP server.
+func NewSinkConfig(cfg *config.Config) SinkConfig {
+	return SinkConfig{
+		OutboxDir:      cfg.Reports.OutboxDir,
+		SMTPHost:       cfg.SMTP.Host,
+		SMTPPort:       cfg.SMTP.Port,
+		SMTPUsername:   cfg.SMTP.Username,
+		SMTPPassword:   cfg.SMTP.Password,
+		SMTPFrom:       cfg.SMTP.From,
+		WebhookTimeout: 30 * time.Second,
+	}
+}
+
+// Sink returns the sink of the given kind
+func (c SinkConfig) Sink(kind string) (Sink, error) {
+	switch kind {
+	case models.SinkFile:
+		return FileSink{Dir: c.OutboxDir}, nil
+	case models.SinkSMTP:
+		return SMTPSink{Host: c.SMTPHost, Port: c.SMTPPort, Username: c.SMTPUsername, Password: c.SMTPPassword, From: c.SMTPFrom}, nil
+	case models.SinkWebhook:
+		return WebhookSink{Client: &http.Client{Timeout: c.WebhookTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink %q", kind)
+	}
+}
+
+// ValidateTarget checks that a schedule's target suits its sink: a relative
+// directory inside the outbox, a list of email addresses, or an http(s) URL
+func ValidateTarget(kind, target string) error {
+	switch kind {
+	case models.SinkFile:
+		if target == "" {
+			return nil
+		}
+		if filepath.IsAbs(target) || !filepath.IsLocal(target) {
+			return errors.New("file target must be a relative directory inside the outbox")
+		}
+	case models.SinkSMTP:
+		if _, err := mail.ParseAddressList(target); err != nil {
+			return errors.New("smtp target must be a comma-separated list of email addresses")
+		}
+	case models.SinkWebhook:
+		parsed, err := url.Parse(target)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("webhook target must be an http or https URL")
+		}
+	default:
+		return fmt.Errorf("sink must be one of %s, %s or %s", models.SinkFile, models.SinkSMTP, models.SinkWebhook)
+	}
+	return nil
+}
+
+// FileSink writes reports into a directory of each tenant, named by the tenant
+// ID, or a subdirectory of it named by the target
+type FileSink struct {
+	Dir string
+}
+
+// Deliver writes the document and returns its path
+func (s FileSink) Deliver(ctx context.Context, schedule models.ReportSchedule, document Document) (string, error) {
+	// Tenants may use the same targets, so each writes under its own directory
+	dir := filepath.Join(s.Dir, strconv.FormatUint(uint64(schedule.TenantID), 10), schedule.Target)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, document.FileName)
+	return path, os.WriteFile(path, document.Body, 0644)
+}
+
+// SMTPSink emails reports as attachments to the recipients in the target
+type SMTPSink struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Deliver sends the document and returns the recipients
+func (s SMTPSink) Deliver(ctx context.Context, schedule models.ReportSchedule, document Document) (string, error) {
+	addresses, err := mail.ParseAddressList(schedule.Target)
+	if err != nil {
+		return "", err
+	}
+	recipients := make([]string, len(addresses))
+	for i, address := range addresses {
+		recipients[i] = address.Address
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	message := mimeMessage(s.From, recipients, schedule.Name, document)
+	if err := smtp.SendMail(addr, auth, s.From, recipients, message); err != nil {
+		return "", err
+	}
+	return strings.Join(recipients, ", "), nil
+}
+
+// mimeMessage builds a multipart email with a short text body and the report attached
+func mimeMessage(from string, to []string, subject string, document Document) []byte {
+	const boundary = "inventory-report-boundary"
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&message, "--%s\r\n", boundary)
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&message, "The scheduled report %q is attached as %s.\r\n\r\n", subject, document.FileName)
+
+	fmt.Fprintf(&message, "--%s\r\n", boundary)
+	fmt.Fprintf(&message, "Content-Type: %s\r\n", document.ContentType)
+	message.WriteString("Content-Transfer-Encoding: base64\r\n")
+	fmt.Fprintf(&message, "Content-Disposition: attachment; filename=%q\r\n\r\n", document.FileName)
+	encoded := base64.StdEncoding.EncodeToString(document.Body)
+	for len(encoded) > 76 {
+		message.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	message.WriteString(encoded + "\r\n")
+	fmt.Fprintf(&message, "--%s--\r\n", boundary)
+
+	return message.Bytes()
+}
+
+// WebhookSink posts reports to the URL in the target
+type WebhookSink struct {
+	Client *http.Client
+}
+
+// Deliver posts the document and returns the URL
+func (s WebhookSink) Deliver(ctx context.Context, schedule models.ReportSchedule, document Document) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, schedule.Target, bytes.NewReader(document.Body))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", document.ContentType)
+	request.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", document.FileName))
+	request.Header.Set("X-Report-Schedule", strconv.FormatUint(uint64(schedule.ID), 10))
+	request.Header.Set("X-Report-Name", schedule.Report)
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return "", fmt.Errorf("webhook responded with %s", response.Status)
+	}
+	return schedule.Target, nil
+}
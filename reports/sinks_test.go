@@ -0,0 +1,250 @@
+// reports/sinks_test.go
+package reports
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"inventory_system/config"
+	"inventory_system/database"
+	"inventory_system/models"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpMessage is one mail received by fakeSMTP
+type smtpMessage struct {
+	From       string
+	Recipients []string
+	Data       string
+}
+
+// fakeSMTP accepts a single SMTP session on a local port and sends the mail
+// it receives on the returned channel
+func fakeSMTP(t *testing.T) (string, int, <-chan smtpMessage) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		var message smtpMessage
+
+		reply("220 localhost fake SMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				message.From = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				message.Recipients = append(message.Recipients, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(dataLine, "."))
+				}
+				message.Data = data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				received <- message
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, received
+}
+
+func TestSMTPSinkDeliversScheduledReport(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.Path = filepath.Join(t.TempDir(), "test.db")
+	cfg.Log.Level = "silent"
+	db, err := database.Connect(&cfg)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	ctx := context.Background()
+	tenantDB := db.WithContext(database.WithTenant(ctx, database.DefaultTenantID))
+	product := models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25}
+	if err := tenantDB.Create(&product).Error; err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	if err := tenantDB.Create(&models.Inventory{ProductID: product.ID, Location: "Store 1", Quantity: 3}).Error; err != nil {
+		t.Fatalf("creating inventory: %v", err)
+	}
+
+	now := time.Date(2026, 6, 15, 8, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Minute)
+	schedule := models.ReportSchedule{
+		Name:      "Low Stock Daily",
+		Report:    "low-stock",
+		Params:    map[string]string{"threshold": "5"},
+		Cron:      "@daily",
+		Format:    "csv",
+		Sink:      models.SinkSMTP,
+		Target:    "Ops Team <ops@example.com>, buyer@example.com",
+		Active:    true,
+		NextRunAt: &due,
+	}
+	if err := tenantDB.Create(&schedule).Error; err != nil {
+		t.Fatalf("creating schedule: %v", err)
+	}
+
+	host, port, received := fakeSMTP(t)
+	sinks := SinkConfig{SMTPHost: host, SMTPPort: port, SMTPFrom: "reports@example.com"}
+	if err := RunDue(ctx, db, sinks, now, time.UTC); err != nil {
+		t.Fatalf("running due schedules: %v", err)
+	}
+
+	var message smtpMessage
+	select {
+	case message = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail was received")
+	}
+
+	if message.From != "reports@example.com" {
+		t.Errorf("mail is from %q, want reports@example.com", message.From)
+	}
+	if got := strings.Join(message.Recipients, ","); got != "ops@example.com,buyer@example.com" {
+		t.Errorf("mail was sent to %q, want ops@example.com,buyer@example.com", got)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(message.Data))
+	if err != nil {
+		t.Fatalf("parsing mail: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != schedule.Name {
+		t.Errorf("subject is %q, want %q", subject, schedule.Name)
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parsing content type: %v", err)
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	var attachment *multipart.Part
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading mail part: %v", err)
+		}
+		if part.FileName() != "" {
+			attachment = part
+			break
+		}
+	}
+	if attachment == nil {
+		t.Fatal("mail has no attachment")
+	}
+
+	wantName := "Low-Stock-Daily-" + now.Format("20060102-150405") + ".csv"
+	if attachment.FileName() != wantName {
+		t.Errorf("attachment is named %q, want %q", attachment.FileName(), wantName)
+	}
+	if contentType := attachment.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+		t.Errorf("attachment has content type %q, want text/csv", contentType)
+	}
+	body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, attachment))
+	if err != nil {
+		t.Fatalf("decoding attachment: %v", err)
+	}
+	if !strings.Contains(string(body), "Desk Lamp") || !strings.Contains(string(body), "Store 1") {
+		t.Errorf("attachment does not list the low stock item:\n%s", body)
+	}
+
+	var delivery models.ReportDelivery
+	if err := tenantDB.Where("schedule_id = ?", schedule.ID).First(&delivery).Error; err != nil {
+		t.Fatalf("reading delivery: %v", err)
+	}
+	if delivery.Status != models.DeliverySucceeded || delivery.Destination != "ops@example.com, buyer@example.com" {
+		t.Errorf("delivery is %s to %q: %s", delivery.Status, delivery.Destination, delivery.Error)
+	}
+}
+
+func TestFileSinkKeepsTenantsApart(t *testing.T) {
+	sink := FileSink{Dir: t.TempDir()}
+	document := Document{FileName: "revenue.csv", ContentType: "text/csv"}
+
+	paths := make(map[uint]string)
+	for _, tenantID := range []uint{1, 2} {
+		document.Body = []byte("tenant " + strconv.FormatUint(uint64(tenantID), 10))
+		schedule := models.ReportSchedule{TenantID: tenantID, Sink: models.SinkFile, Target: "weekly"}
+		path, err := sink.Deliver(context.Background(), schedule, document)
+		if err != nil {
+			t.Fatalf("delivering for tenant %d: %v", tenantID, err)
+		}
+		paths[tenantID] = path
+	}
+
+	for tenantID, path := range paths {
+		want := filepath.Join(sink.Dir, strconv.FormatUint(uint64(tenantID), 10), "weekly", "revenue.csv")
+		if path != want {
+			t.Errorf("tenant %d's report went to %s, want %s", tenantID, path, want)
+		}
+		body, err := os.ReadFile(want)
+		if err != nil {
+			t.Fatalf("reading tenant %d's report: %v", tenantID, err)
+		}
+		if string(body) != "tenant "+strconv.FormatUint(uint64(tenantID), 10) {
+			t.Errorf("tenant %d's report holds %q", tenantID, body)
+		}
+	}
+}
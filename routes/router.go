@@ -2,7 +2,14 @@
 package routes
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"inventory_system/auth"
+	"inventory_system/config"
+	"inventory_system/database"
 	"inventory_system/handlers"
+	"inventory_system/reports"
+	"inventory_system/services"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,14 +17,15 @@ import (
 )
 
 // SetupRouter configures all the routes for our application
-func SetupRouter(db *gorm.DB) *gin.Engine {
+func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	r := gin.Default()
 
 	// Middleware for CORS
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -27,46 +35,165 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 		c.Next()
 	})
 
-	// Initialize handlers
-	productHandler := &handlers.ProductHandler{DB: db}
-	inventoryHandler := &handlers.InventoryHandler{DB: db}
-	orderHandler := &handlers.OrderHandler{DB: db}
+	// Middleware tagging each request with an ID, taken from the client when it
+	// sends one, that is returned to it and recorded in the audit log
+	r.Use(func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-ID")
+		if requestID == "" || len(requestID) > 64 {
+			id := make([]byte, 16)
+			rand.Read(id)
+			requestID = hex.EncodeToString(id)
+		}
+		c.Writer.Header().Set("X-Request-ID", requestID)
+		c.Request = c.Request.WithContext(database.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	})
 
-	// Static file serving
-	r.Static("/uploads", "./uploads")
+	// Initialize handlers
+	reportLocation := cfg.ReportLocation()
+	approval := services.ApprovalPolicy{MaxQuantity: cfg.Inventory.ApprovalQuantity, MaxValue: cfg.Inventory.ApprovalValue}
+	products := &services.GormProductService{DB: db}
+	inventory := &services.GormInventoryService{DB: db, Approval: approval}
+	orders := &services.GormOrderService{DB: db}
+	productHandler := &handlers.ProductHandler{DB: db, Products: products, UploadDir: cfg.Server.UploadDir, Location: reportLocation}
+	inventoryHandler := &handlers.InventoryHandler{DB: db, Inventory: inventory, Location: reportLocation}
+	orderHandler := &handlers.OrderHandler{DB: db, Orders: orders}
+	binHandler := &handlers.BinHandler{Bins: &services.GormBinService{DB: db}}
+	countHandler := &handlers.CountHandler{Counts: &services.GormCountService{DB: db}}
+	reportHandler := &handlers.ReportHandler{DB: db, Location: reportLocation}
+	forecastHandler := &handlers.ForecastHandler{DB: db, Location: reportLocation}
+	scheduleHandler := &handlers.ScheduleHandler{DB: db, Sinks: reports.NewSinkConfig(cfg), Location: reportLocation}
+	tokens := auth.NewTokens(cfg.Auth)
+	apiKeys := &services.GormAPIKeyService{DB: db}
+	authHandler := &handlers.AuthHandler{Users: &services.GormUserService{DB: db}, Tokens: tokens}
+	apiKeyHandler := &handlers.APIKeyHandler{Keys: apiKeys}
+	auditHandler := &handlers.AuditHandler{DB: db}
+	tenantHandler := &handlers.TenantHandler{Tenants: &services.GormTenantService{DB: db}}
+	webhookHandler := &handlers.WebhookHandler{DB: db}
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "up"})
 	})
 
+	// Authentication routes
+	authRoutes := r.Group("/auth")
+	{
+		authRoutes.POST("/login", authHandler.Login)
+		authRoutes.POST("/refresh", authHandler.Refresh)
+	}
+
+	// Every other route needs a signed-in user whose role grants the route's
+	// permission
+	api := r.Group("", auth.Middleware(tokens, apiKeys))
+
+	// Static file serving
+	api.Group("", auth.Require(auth.ProductsRead)).Static("/uploads", cfg.Server.UploadDir)
+
 	// Product routes
-	productRoutes := r.Group("/products")
+	productRoutes := api.Group("/products")
 	{
-		productRoutes.GET("", productHandler.GetProducts)
-		productRoutes.GET("/:id", productHandler.GetProduct)
-		productRoutes.POST("", productHandler.CreateProduct)
-		productRoutes.PUT("/:id", productHandler.UpdateProduct)
-		productRoutes.POST("/:id/upload", productHandler.UploadProductImage)
-		productRoutes.GET("/:id/image", productHandler.GetProductImage)
+		productRoutes.GET("", auth.Require(auth.ProductsRead), productHandler.GetProducts)
+		productRoutes.GET("/:id", auth.Require(auth.ProductsRead), productHandler.GetProduct)
+		productRoutes.POST("", auth.Require(auth.ProductsWrite), productHandler.CreateProduct)
+		productRoutes.POST("/classify", auth.Require(auth.ProductsWrite), productHandler.ClassifyProducts)
+		productRoutes.PUT("/:id", auth.Require(auth.ProductsWrite), productHandler.UpdateProduct)
+		productRoutes.POST("/:id/upload", auth.Require(auth.ProductsWrite), productHandler.UploadProductImage)
+		productRoutes.GET("/:id/image", auth.Require(auth.ProductsRead), productHandler.GetProductImage)
+		productRoutes.GET("/:id/forecast", auth.Require(auth.ReportsRead), forecastHandler.GetForecast)
+		productRoutes.GET("/:id/forecast/backtest", auth.Require(auth.ReportsRead), forecastHandler.GetBacktest)
 	}
 
 	// Inventory routes
-	inventoryRoutes := r.Group("/inventory")
+	inventoryRoutes := api.Group("/inventory")
 	{
-		inventoryRoutes.GET("", inventoryHandler.GetInventory)
-		inventoryRoutes.PATCH("/:product_id", inventoryHandler.AdjustStock)
-		inventoryRoutes.GET("/locations", inventoryHandler.GetInventoryByLocation)
-		inventoryRoutes.GET("/low-stock", inventoryHandler.GetLowStockProducts)
+		inventoryRoutes.GET("", auth.Require(auth.InventoryRead), inventoryHandler.GetInventory)
+		inventoryRoutes.PATCH("/:product_id", auth.Require(auth.InventoryAdjust), inventoryHandler.AdjustStock)
+		inventoryRoutes.GET("/locations", auth.Require(auth.InventoryRead), inventoryHandler.GetInventoryByLocation)
+		inventoryRoutes.GET("/low-stock", auth.Require(auth.InventoryRead), inventoryHandler.GetLowStockProducts)
+		inventoryRoutes.GET("/bins", auth.Require(auth.InventoryRead), binHandler.GetBins)
+		inventoryRoutes.GET("/bins/:id", auth.Require(auth.InventoryRead), binHandler.GetBin)
+		inventoryRoutes.POST("/bins", auth.Require(auth.InventoryAdjust), binHandler.CreateBin)
+		inventoryRoutes.POST("/bins/move", auth.Require(auth.InventoryAdjust), binHandler.MoveStock)
+		inventoryRoutes.POST("/putaway", auth.Require(auth.InventoryAdjust), binHandler.Putaway)
+		inventoryRoutes.GET("/movements", auth.Require(auth.InventoryRead), inventoryHandler.GetMovements)
+		inventoryRoutes.GET("/valuation", auth.Require(auth.InventoryRead), inventoryHandler.GetValuation)
+		inventoryRoutes.GET("/adjustments", auth.Require(auth.InventoryRead), inventoryHandler.GetAdjustments)
+		inventoryRoutes.POST("/adjustments/:id/approve", auth.Require(auth.InventoryApprove), inventoryHandler.ApproveAdjustment)
+		inventoryRoutes.POST("/adjustments/:id/reject", auth.Require(auth.InventoryApprove), inventoryHandler.RejectAdjustment)
+	}
+
+	// Cycle count routes
+	countRoutes := api.Group("/counts")
+	{
+		countRoutes.GET("", auth.Require(auth.InventoryRead), countHandler.GetCounts)
+		countRoutes.GET("/:id", auth.Require(auth.InventoryRead), countHandler.GetCount)
+		countRoutes.POST("", auth.Require(auth.InventoryCount), countHandler.CreateCount)
+		countRoutes.POST("/schedule", auth.Require(auth.InventoryCount), countHandler.ScheduleCount)
+		countRoutes.POST("/:id/entries", auth.Require(auth.InventoryCount), countHandler.SubmitCounts)
+		countRoutes.GET("/:id/variance", auth.Require(auth.InventoryApprove), countHandler.GetVariance)
+		countRoutes.POST("/:id/approve", auth.Require(auth.InventoryApprove), countHandler.ApproveCount)
+		countRoutes.POST("/:id/cancel", auth.Require(auth.InventoryCount), countHandler.CancelCount)
 	}
 
 	// Order routes
-	orderRoutes := r.Group("/orders")
+	orderRoutes := api.Group("/orders")
+	{
+		orderRoutes.GET("", auth.Require(auth.OrdersRead), orderHandler.GetOrders)
+		orderRoutes.GET("/:id", auth.Require(auth.OrdersRead), orderHandler.GetOrder)
+		orderRoutes.POST("", auth.Require(auth.OrdersCreate), orderHandler.CreateOrder)
+		orderRoutes.GET("/revenue", auth.Require(auth.ReportsRead), orderHandler.GetRevenueByCategory)
+		orderRoutes.GET("/margin", auth.Require(auth.ReportsRead), orderHandler.GetGrossMargin)
+		orderRoutes.GET("/margin/products", auth.Require(auth.ReportsRead), orderHandler.GetProductsByMargin)
+	}
+
+	// Reporting routes
+	reportRoutes := api.Group("/reports")
+	{
+		reportRoutes.GET("/sales", auth.Require(auth.ReportsRead), reportHandler.GetSales)
+		reportRoutes.GET("/revenue", auth.Require(auth.ReportsRead), reportHandler.GetRevenue)
+		reportRoutes.GET("/top-sellers", auth.Require(auth.ReportsRead), reportHandler.GetTopSellers)
+		reportRoutes.GET("/inventory-value", auth.Require(auth.ReportsRead), reportHandler.GetInventoryValue)
+		reportRoutes.GET("/stock-distribution", auth.Require(auth.ReportsRead), reportHandler.GetStockDistribution)
+		reportRoutes.GET("/low-stock", auth.Require(auth.ReportsRead), reportHandler.GetLowStock)
+		reportRoutes.GET("/turnover", auth.Require(auth.ReportsRead), reportHandler.GetTurnover)
+		reportRoutes.GET("/days-of-supply", auth.Require(auth.ReportsRead), reportHandler.GetDaysOfSupply)
+		reportRoutes.GET("/dead-stock", auth.Require(auth.ReportsRead), reportHandler.GetDeadStock)
+		reportRoutes.GET("/schedules", auth.Require(auth.ReportsRead), scheduleHandler.GetSchedules)
+		reportRoutes.POST("/schedules", auth.Require(auth.ReportsSchedule), scheduleHandler.CreateSchedule)
+		reportRoutes.GET("/schedules/:id", auth.Require(auth.ReportsRead), scheduleHandler.GetSchedule)
+		reportRoutes.PUT("/schedules/:id", auth.Require(auth.ReportsSchedule), scheduleHandler.UpdateSchedule)
+		reportRoutes.DELETE("/schedules/:id", auth.Require(auth.ReportsSchedule), scheduleHandler.DeleteSchedule)
+		reportRoutes.POST("/schedules/:id/run", auth.Require(auth.ReportsSchedule), scheduleHandler.RunSchedule)
+		reportRoutes.GET("/schedules/:id/deliveries", auth.Require(auth.ReportsRead), scheduleHandler.GetDeliveries)
+		reportRoutes.GET("/deliveries", auth.Require(auth.ReportsRead), scheduleHandler.GetDeliveries)
+	}
+
+	// Audit log routes
+	api.GET("/audit", auth.Require(auth.AuditRead), auditHandler.GetAuditEntries)
+
+	// Tenant settings routes
+	api.GET("/tenant", auth.Require(auth.ProductsRead), tenantHandler.GetTenant)
+	api.PUT("/tenant", auth.Require(auth.TenantManage), tenantHandler.UpdateTenant)
+
+	// API key routes
+	apiKeyRoutes := api.Group("/api-keys")
+	{
+		apiKeyRoutes.GET("", auth.Require(auth.APIKeysManage), apiKeyHandler.GetAPIKeys)
+		apiKeyRoutes.POST("", auth.Require(auth.APIKeysManage), apiKeyHandler.CreateAPIKey)
+		apiKeyRoutes.DELETE("/:id", auth.Require(auth.APIKeysManage), apiKeyHandler.RevokeAPIKey)
+	}
+
+	// Webhook routes
+	webhookRoutes := api.Group("/webhooks")
 	{
-		orderRoutes.GET("", orderHandler.GetOrders)
-		orderRoutes.GET("/:id", orderHandler.GetOrder)
-		orderRoutes.POST("", orderHandler.CreateOrder)
-		orderRoutes.GET("/revenue", orderHandler.GetRevenueByCategory)
+		webhookRoutes.GET("", auth.Require(auth.WebhooksManage), webhookHandler.GetWebhooks)
+		webhookRoutes.POST("", auth.Require(auth.WebhooksManage), webhookHandler.CreateWebhook)
+		webhookRoutes.GET("/:id", auth.Require(auth.WebhooksManage), webhookHandler.GetWebhook)
+		webhookRoutes.PUT("/:id", auth.Require(auth.WebhooksManage), webhookHandler.UpdateWebhook)
+		webhookRoutes.DELETE("/:id", auth.Require(auth.WebhooksManage), webhookHandler.DeleteWebhook)
+		webhookRoutes.GET("/:id/deliveries", auth.Require(auth.WebhooksManage), webhookHandler.GetDeliveries)
+		webhookRoutes.POST("/:id/replay", auth.Require(auth.WebhooksManage), webhookHandler.ReplayDeliveries)
 	}
 
 	return r
@@ -0,0 +1,149 @@
+// routes/router_test.go
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"inventory_system/auth"
+	"inventory_system/config"
+	"inventory_system/database"
+	"inventory_system/services"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// client sends requests to the router as one signed-in user or API key
+type client struct {
+	router *gin.Engine
+	header string
+	value  string
+}
+
+func (c client) do(method, path, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if c.header != "" {
+		request.Header.Set(c.header, c.value)
+	}
+	recorder := httptest.NewRecorder()
+	c.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// login signs a user in through the API and returns a client using the access token
+func login(t *testing.T, router *gin.Engine, username, password string) client {
+	t.Helper()
+
+	response := client{router: router}.do(http.MethodPost, "/auth/login",
+		`{"username":"`+username+`","password":"`+password+`"}`)
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+	}
+	if response.Code != http.StatusOK || json.Unmarshal(response.Body.Bytes(), &tokens) != nil {
+		t.Fatalf("signing in as %s: %d %s", username, response.Code, response.Body)
+	}
+	return client{router: router, header: "Authorization", value: "Bearer " + tokens.AccessToken}
+}
+
+func TestTenantCannotReachAnotherTenantsData(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.Path = filepath.Join(t.TempDir(), "test.db")
+	cfg.Server.UploadDir = t.TempDir()
+	cfg.Log.Level = "silent"
+	cfg.Auth.Secret = "0123456789abcdef0123456789abcdef"
+
+	db, err := database.Connect(&cfg)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	users := &services.GormUserService{DB: db}
+	beta, err := (&services.GormTenantService{DB: db}).Create(services.TenantRequest{Name: "Beta"})
+	if err != nil {
+		t.Fatalf("creating tenant: %v", err)
+	}
+	for _, request := range []services.UserRequest{
+		{TenantID: database.DefaultTenantID, Username: "alice", Password: "password1", Role: auth.RoleAdmin},
+		{TenantID: beta.ID, Username: "bob", Password: "password1", Role: auth.RoleAdmin},
+	} {
+		if _, err := users.Create(request); err != nil {
+			t.Fatalf("creating user %s: %v", request.Username, err)
+		}
+	}
+	_, key, err := (&services.GormAPIKeyService{DB: db}).Create(database.WithTenant(context.Background(), beta.ID), services.APIKeyRequest{
+		Name:   "beta-sync",
+		Scopes: []string{auth.ProductsRead, auth.ProductsWrite, auth.InventoryRead, auth.InventoryAdjust},
+	})
+	if err != nil {
+		t.Fatalf("creating API key: %v", err)
+	}
+
+	router := SetupRouter(db, &cfg)
+	alice := login(t, router, "alice", "password1")
+
+	// Tenant A stocks a product through the API
+	response := alice.do(http.MethodPost, "/products", `{"name":"Desk Lamp","price":25,"category":"Electronics"}`)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("creating product: %d %s", response.Code, response.Body)
+	}
+	var product struct {
+		ID uint `json:"id"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &product)
+	id := strconv.FormatUint(uint64(product.ID), 10)
+	productPath := "/products/" + id
+	inventoryPath := "/inventory/" + id + "?location=Store%201"
+	if response := alice.do(http.MethodPatch, inventoryPath, `{"action":"add","value":10,"reason":"found"}`); response.Code != http.StatusOK {
+		t.Fatalf("stocking product: %d %s", response.Code, response.Body)
+	}
+
+	intruders := map[string]client{
+		"JWT":     login(t, router, "bob", "password1"),
+		"API key": {router: router, header: "X-API-Key", value: key},
+	}
+	for name, intruder := range intruders {
+		t.Run(name, func(t *testing.T) {
+			if response := intruder.do(http.MethodGet, productPath, ""); response.Code != http.StatusNotFound {
+				t.Errorf("reading the product got %d %s, want 404", response.Code, response.Body)
+			}
+			if response := intruder.do(http.MethodPut, productPath, `{"price":1}`); response.Code != http.StatusNotFound {
+				t.Errorf("updating the product got %d %s, want 404", response.Code, response.Body)
+			}
+			if response := intruder.do(http.MethodGet, "/inventory?product_id="+id, ""); response.Code != http.StatusOK || strings.Contains(response.Body.String(), "Store 1") {
+				t.Errorf("listing the stock got %d %s, want 200 without the product", response.Code, response.Body)
+			}
+			if response := intruder.do(http.MethodPatch, inventoryPath, `{"action":"remove","value":10,"reason":"theft"}`); response.Code != http.StatusNotFound {
+				t.Errorf("adjusting the stock got %d %s, want 404", response.Code, response.Body)
+			}
+		})
+	}
+
+	// Tenant A still sees its product and stock unchanged
+	response = alice.do(http.MethodGet, productPath, "")
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"price":25`) {
+		t.Errorf("tenant A's product is now %d %s, want it unchanged", response.Code, response.Body)
+	}
+	response = alice.do(http.MethodGet, "/inventory?product_id="+id, "")
+	if !strings.Contains(response.Body.String(), `"quantity":10`) {
+		t.Errorf("tenant A's stock is now %s, want 10", response.Body)
+	}
+}
@@ -0,0 +1,160 @@
+// services/apikeys.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"inventory_system/auth"
+	"inventory_system/database"
+	"inventory_system/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix starts every API key so that leaked keys are easy to recognise
+const apiKeyPrefix = "inv_"
+
+// apiKeyUseInterval is how stale a key's last use may get before it is
+// recorded again, so that busy clients do not write on every request
+const apiKeyUseInterval = time.Minute
+
+// GormAPIKeyService is the APIKeyService backed by the database
+type GormAPIKeyService struct {
+	DB *gorm.DB
+}
+
+// Create issues a new key and returns its record and the key itself
+func (s *GormAPIKeyService) Create(ctx context.Context, request APIKeyRequest) (models.APIKey, string, error) {
+	key, record, err := newAPIKey(request)
+	if err != nil {
+		return record, "", err
+	}
+	if err := s.DB.WithContext(ctx).Create(&record).Error; err != nil {
+		return record, "", err
+	}
+	return record, key, nil
+}
+
+// List retrieves all keys, oldest first
+func (s *GormAPIKeyService) List(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := s.DB.WithContext(ctx).Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke stops a key from authenticating
+func (s *GormAPIKeyService) Revoke(ctx context.Context, id uint) (models.APIKey, error) {
+	db := s.DB.WithContext(ctx)
+
+	var record models.APIKey
+	if err := db.First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return record, ErrAPIKeyNotFound
+		}
+		return record, err
+	}
+	if record.RevokedAt != nil {
+		return record, ErrAPIKeyRevoked
+	}
+
+	now := time.Now()
+	record.RevokedAt = &now
+	return record, db.Model(&record).Update("revoked_at", now).Error
+}
+
+// Authenticate returns the usable key with the given value and records its use
+func (s *GormAPIKeyService) Authenticate(key string) (models.APIKey, error) {
+	db := s.DB.WithContext(database.AllTenants(context.Background()))
+
+	var record models.APIKey
+	err := db.Where("key_hash = ?", HashAPIKey(key)).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return record, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return record, err
+	}
+
+	now := time.Now()
+	if !usableAPIKey(record, now) {
+		return record, ErrInvalidAPIKey
+	}
+	if staleAPIKeyUse(record, now) {
+		record.LastUsedAt = &now
+		if err := db.Model(&record).Update("last_used_at", now).Error; err != nil {
+			return record, err
+		}
+	}
+	return record, nil
+}
+
+// HashAPIKey returns the hash under which a key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAPIKey checks a request and generates a random key and the record that
+// identifies it
+func newAPIKey(request APIKeyRequest) (string, models.APIKey, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return "", models.APIKey{}, ErrAPIKeyNameRequired
+	}
+
+	scopes := []string{}
+	for _, scope := range request.Scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if !auth.IsValidPermission(scope) {
+			return "", models.APIKey{}, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return "", models.APIKey{}, ErrAPIKeyScopesRequired
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return "", models.APIKey{}, ErrAPIKeyExpiryPast
+	}
+
+	var locations []string
+	for _, location := range request.Locations {
+		if location = strings.TrimSpace(location); location != "" {
+			locations = append(locations, location)
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", models.APIKey{}, err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	return key, models.APIKey{
+		Name:      name,
+		Prefix:    key[:len(apiKeyPrefix)+8],
+		KeyHash:   HashAPIKey(key),
+		Scopes:    scopes,
+		Locations: locations,
+		ExpiresAt: request.ExpiresAt,
+	}, nil
+}
+
+// usableAPIKey reports whether a key is neither revoked nor expired
+func usableAPIKey(record models.APIKey, now time.Time) bool {
+	return record.RevokedAt == nil && (record.ExpiresAt == nil || now.Before(*record.ExpiresAt))
+}
+
+// staleAPIKeyUse reports whether a key's last use should be recorded again
+func staleAPIKeyUse(record models.APIKey, now time.Time) bool {
+	return record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= apiKeyUseInterval
+}
@@ -0,0 +1,167 @@
+// services/bins.go
+package services
+
+import (
+	"context"
+	"errors"
+	"inventory_system/database"
+	"inventory_system/models"
+
+	"gorm.io/gorm"
+)
+
+// GormBinService is the BinService backed by the database
+type GormBinService struct {
+	DB *gorm.DB
+}
+
+// List retrieves bins, optionally at one location, in location and code order
+func (s *GormBinService) List(ctx context.Context, location string) ([]models.Bin, error) {
+	var bins []models.Bin
+	db := s.DB.WithContext(ctx)
+
+	if location != "" {
+		db = db.Where("location = ?", location)
+	}
+
+	err := db.Order("location, zone, aisle, shelf, code").Find(&bins).Error
+	return bins, err
+}
+
+// Get retrieves a single bin
+func (s *GormBinService) Get(ctx context.Context, id uint) (models.Bin, error) {
+	return findBin(s.DB.WithContext(ctx), id)
+}
+
+// Stock retrieves the products a bin holds
+func (s *GormBinService) Stock(ctx context.Context, binID uint) ([]models.BinStock, error) {
+	var stock []models.BinStock
+	err := s.DB.WithContext(ctx).Preload("Product").Where("bin_id = ? AND quantity > 0", binID).Find(&stock).Error
+	return stock, err
+}
+
+// Create adds a bin to one of the tenant's locations
+func (s *GormBinService) Create(ctx context.Context, bin *models.Bin) error {
+	if bin.Code == models.ReceivingBin {
+		return ErrBinCodeReserved
+	}
+
+	db := s.DB.WithContext(ctx)
+	if err := checkLocation(db, bin.Location); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := db.Model(&models.Bin{}).Where("location = ? AND code = ?", bin.Location, bin.Code).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrBinExists
+	}
+
+	return db.Create(bin).Error
+}
+
+// Putaway moves received stock from a location's receiving bin into a storage bin
+func (s *GormBinService) Putaway(ctx context.Context, request PutawayRequest) error {
+	db := s.DB.WithContext(ctx)
+
+	bin, err := findBin(db, request.BinID)
+	if err != nil {
+		return err
+	}
+	if err := checkProduct(db, request.ProductID); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		receiving, err := database.GetReceivingBin(tx, bin.Location)
+		if err != nil {
+			return err
+		}
+		if err := database.RemoveFromBin(tx, request.ProductID, receiving, request.Quantity); err != nil {
+			return err
+		}
+		if err := database.AddToBin(tx, request.ProductID, bin, request.Quantity); err != nil {
+			return err
+		}
+		return recordBinMove(tx, models.MovementPutaway, request.ProductID, receiving, bin, request.Quantity)
+	})
+}
+
+// Move transfers stock of a product from one bin to another, updating the
+// location totals when the bins are at different locations
+func (s *GormBinService) Move(ctx context.Context, request BinMoveRequest) error {
+	db := s.DB.WithContext(ctx)
+
+	from, err := findBin(db, request.FromBinID)
+	if err != nil {
+		return err
+	}
+	to, err := findBin(db, request.ToBinID)
+	if err != nil {
+		return err
+	}
+	if err := checkProduct(db, request.ProductID); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := database.RemoveFromBin(tx, request.ProductID, from, request.Quantity); err != nil {
+			return err
+		}
+		if err := database.AddToBin(tx, request.ProductID, to, request.Quantity); err != nil {
+			return err
+		}
+		if err := recordBinMove(tx, models.MovementTransfer, request.ProductID, from, to, request.Quantity); err != nil {
+			return err
+		}
+
+		// Moving between locations changes both location totals
+		if from.Location != to.Location {
+			if _, err := database.SyncInventory(tx, request.ProductID, from.Location); err != nil {
+				return err
+			}
+			if _, err := database.SyncInventory(tx, request.ProductID, to.Location); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// findBin loads a bin, mapping a missing one to ErrBinNotFound
+func findBin(db *gorm.DB, id uint) (models.Bin, error) {
+	var bin models.Bin
+	err := db.First(&bin, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return bin, ErrBinNotFound
+	}
+	return bin, err
+}
+
+// checkProduct makes sure a product exists, returning ErrProductNotFound when it does not
+func checkProduct(db *gorm.DB, id uint) error {
+	var count int64
+	if err := db.Model(&models.Product{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// recordBinMove writes the outbound and inbound legs of a bin-to-bin move to the ledger
+func recordBinMove(tx *gorm.DB, movementType string, productID uint, from, to models.Bin, quantity int) error {
+	legs := []models.StockMovement{
+		{ProductID: productID, Location: from.Location, BinID: &from.ID, Quantity: -quantity, Type: movementType},
+		{ProductID: productID, Location: to.Location, BinID: &to.ID, Quantity: quantity, Type: movementType},
+	}
+	for i := range legs {
+		if err := database.RecordMovement(tx, &legs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
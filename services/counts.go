@@ -0,0 +1,299 @@
+// services/counts.go
+package services
+
+import (
+	"context"
+	"errors"
+	"inventory_system/database"
+	"inventory_system/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormCountService is the CountService backed by the database
+type GormCountService struct {
+	DB *gorm.DB
+}
+
+// List retrieves count sessions, optionally with one status, newest first
+func (s *GormCountService) List(ctx context.Context, status string) ([]models.CountSession, error) {
+	var sessions []models.CountSession
+	db := s.DB.WithContext(ctx)
+
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	err := db.Order("created_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// Get retrieves a count session with its lines and their products
+func (s *GormCountService) Get(ctx context.Context, id uint) (models.CountSession, error) {
+	var session models.CountSession
+	err := s.DB.WithContext(ctx).Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("location, product_id")
+	}).Preload("Lines.Product").First(&session, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return session, ErrCountNotFound
+	}
+	return session, err
+}
+
+// Open starts a count session over the inventory in scope and snapshots the
+// expected quantities
+func (s *GormCountService) Open(ctx context.Context, request CountRequest) (models.CountSession, error) {
+	session := models.CountSession{
+		Location: request.Location,
+		Status:   models.CountOpen,
+		Note:     request.Note,
+	}
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inventories []models.Inventory
+		query := tx
+		if request.Location != "" {
+			query = query.Where("location = ?", request.Location)
+		}
+		if len(request.ProductIDs) > 0 {
+			query = query.Where("product_id IN ?", request.ProductIDs)
+		}
+		if err := query.Order("location, product_id").Find(&inventories).Error; err != nil {
+			return err
+		}
+		if len(inventories) == 0 {
+			return ErrNothingToCount
+		}
+
+		return database.OpenCountSession(tx, &session, inventories)
+	})
+	return session, err
+}
+
+// Schedule opens a count session for the items at a location that are due
+// for counting, at most limit of them when limit is positive
+func (s *GormCountService) Schedule(ctx context.Context, location string, limit int) (models.CountSession, error) {
+	session := models.CountSession{
+		Location: location,
+		Status:   models.CountOpen,
+		Note:     "Scheduled cycle count",
+	}
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		due, err := database.GetCountsDue(tx, location, time.Now())
+		if err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return ErrNothingToCount
+		}
+		if limit > 0 && len(due) > limit {
+			due = due[:limit]
+		}
+
+		// The count snapshot does not need the product details
+		for i := range due {
+			due[i].Product = models.Product{}
+		}
+		return database.OpenCountSession(tx, &session, due)
+	})
+	return session, err
+}
+
+// Submit records counted quantities on the lines of an open session. The
+// session is locked so counts cannot land after it was approved or cancelled.
+func (s *GormCountService) Submit(ctx context.Context, id uint, entries []CountEntry) error {
+	now := time.Now()
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var session models.CountSession
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ?", models.CountOpen).First(&session, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCountNotOpen
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			result := tx.Model(&models.CountLine{}).
+				Where("id = ? AND session_id = ?", entry.LineID, id).
+				Updates(map[string]interface{}{"counted_quantity": entry.Quantity, "counted_at": now})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrCountLineNotFound
+			}
+		}
+		return nil
+	})
+}
+
+// Variance reports expected against counted quantities for a session
+func (s *GormCountService) Variance(ctx context.Context, id uint) (CountVarianceReport, error) {
+	db := s.DB.WithContext(ctx)
+
+	session, err := findSession(db, id)
+	if err != nil {
+		return CountVarianceReport{}, err
+	}
+	return buildVarianceReport(db, session)
+}
+
+// Approve posts the variances of a fully counted session and closes it. It
+// fails with ErrCountIncomplete, reporting the uncounted lines, while lines
+// are left to count.
+func (s *GormCountService) Approve(ctx context.Context, id uint) (CountVarianceReport, error) {
+	db := s.DB.WithContext(ctx)
+
+	session, err := findSession(db, id)
+	if err != nil {
+		return CountVarianceReport{}, err
+	}
+
+	uncounted := 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Closing the session first means only one approval or cancellation wins
+		now := time.Now()
+		if err := closeSession(tx, id, models.CountApproved, now); err != nil {
+			return err
+		}
+		session.Status = models.CountApproved
+		session.ClosedAt = &now
+
+		var lines []models.CountLine
+		if err := tx.Where("session_id = ?", id).Find(&lines).Error; err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if line.CountedQuantity == nil {
+				uncounted++
+			}
+		}
+		if uncounted > 0 {
+			return ErrCountIncomplete
+		}
+
+		for _, line := range lines {
+			if err := database.PostCountVariance(tx, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrCountNotOpen):
+		session, _ = findSession(db, id)
+		return CountVarianceReport{Session: session}, err
+	case errors.Is(err, ErrCountIncomplete):
+		session.Status = models.CountOpen
+		session.ClosedAt = nil
+		return CountVarianceReport{Session: session, UncountedLines: uncounted}, err
+	case err != nil:
+		return CountVarianceReport{Session: session}, err
+	}
+
+	return buildVarianceReport(db, session)
+}
+
+// Cancel closes an open count session without posting any adjustments
+func (s *GormCountService) Cancel(ctx context.Context, id uint) (models.CountSession, error) {
+	db := s.DB.WithContext(ctx)
+
+	if err := closeSession(db, id, models.CountCancelled, time.Now()); err != nil {
+		if errors.Is(err, ErrCountNotOpen) {
+			session, findErr := findSession(db, id)
+			if findErr != nil {
+				return session, findErr
+			}
+			return session, err
+		}
+		return models.CountSession{}, err
+	}
+	return findSession(db, id)
+}
+
+// findSession loads a count session without its lines, mapping a missing one
+// to ErrCountNotFound
+func findSession(db *gorm.DB, id uint) (models.CountSession, error) {
+	var session models.CountSession
+	err := db.First(&session, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return session, ErrCountNotFound
+	}
+	return session, err
+}
+
+// closeSession moves a session from open to status, returning ErrCountNotOpen
+// when it is no longer open
+func closeSession(db *gorm.DB, id uint, status string, at time.Time) error {
+	result := db.Model(&models.CountSession{}).
+		Where("id = ? AND status = ?", id, models.CountOpen).
+		Updates(map[string]interface{}{"status": status, "closed_at": at})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCountNotOpen
+	}
+	return nil
+}
+
+// buildVarianceReport computes per-line and total variances for a count session
+func buildVarianceReport(db *gorm.DB, session models.CountSession) (CountVarianceReport, error) {
+	report := CountVarianceReport{Session: session, Lines: []CountVariance{}}
+
+	var lines []models.CountLine
+	if err := db.Preload("Product").Where("session_id = ?", session.ID).Order("location, product_id").Find(&lines).Error; err != nil {
+		return report, err
+	}
+
+	flagged, err := database.CountFlaggedMovements(db, session.ID)
+	if err != nil {
+		return report, err
+	}
+
+	// Approved variances are valued at what was posted; open ones at what
+	// posting them would book under the costing method
+	var posted map[string]float64
+	if session.Status == models.CountApproved {
+		if posted, err = database.CountPostedValues(db, session.ID); err != nil {
+			return report, err
+		}
+	}
+
+	for _, line := range lines {
+		variance := CountVariance{
+			LineID:           line.ID,
+			ProductID:        line.ProductID,
+			ProductName:      line.Product.Name,
+			Location:         line.Location,
+			Expected:         line.ExpectedQuantity,
+			Counted:          line.CountedQuantity,
+			FlaggedMovements: flagged[database.CountLineKey(line.ProductID, line.Location)],
+		}
+
+		if line.CountedQuantity == nil {
+			report.UncountedLines++
+		} else {
+			diff := *line.CountedQuantity - line.ExpectedQuantity
+			value, ok := posted[database.CountLineKey(line.ProductID, line.Location)]
+			if !ok {
+				if value, err = database.VarianceValue(db, line.Product, diff); err != nil {
+					return report, err
+				}
+			}
+			variance.Variance = &diff
+			variance.VarianceValue = &value
+			report.TotalVariance += diff
+			report.TotalVarianceValue += value
+		}
+
+		report.Lines = append(report.Lines, variance)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,212 @@
+// services/inventory.go
+package services
+
+import (
+	"context"
+	"errors"
+	"inventory_system/database"
+	"inventory_system/models"
+
+	"gorm.io/gorm"
+)
+
+// GormInventoryService is the InventoryService backed by the database
+type GormInventoryService struct {
+	DB *gorm.DB
+	// Approval holds the thresholds above which adjustments need approval
+	Approval ApprovalPolicy
+}
+
+// List retrieves inventory with its products, current or as of a past moment
+func (s *GormInventoryService) List(ctx context.Context, filter InventoryFilter) ([]models.Inventory, error) {
+	db := s.DB.WithContext(ctx)
+
+	// Historical balances are rebuilt from the movement ledger
+	if filter.AsOf != nil {
+		return database.GetInventoryAsOf(db, *filter.AsOf, filter.ProductID, filter.Location)
+	}
+
+	var inventories []models.Inventory
+	if filter.ProductID != 0 {
+		db = db.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.Location != "" {
+		db = db.Where("location = ?", filter.Location)
+	}
+
+	err := db.Preload("Product").Find(&inventories).Error
+	return inventories, err
+}
+
+// Adjust applies a manual adjustment, or queues it when the approval policy
+// requires a second pair of eyes
+func (s *GormInventoryService) Adjust(ctx context.Context, request AdjustmentRequest) (AdjustmentResult, error) {
+	db := s.DB.WithContext(ctx)
+
+	var product models.Product
+	if err := db.First(&product, request.ProductID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return AdjustmentResult{}, ErrProductNotFound
+		}
+		return AdjustmentResult{}, err
+	}
+
+	adjustment, err := newAdjustment(request, product)
+	if err != nil {
+		return AdjustmentResult{}, err
+	}
+	if err := checkLocation(db, adjustment.Location); err != nil {
+		return AdjustmentResult{}, err
+	}
+
+	if adjustment.BinID != nil {
+		var bin models.Bin
+		if err := db.Where("location = ?", adjustment.Location).First(&bin, *adjustment.BinID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return AdjustmentResult{}, ErrBinNotFound
+			}
+			return AdjustmentResult{}, err
+		}
+	}
+
+	// Large adjustments wait in the approval queue instead of applying immediately
+	if s.Approval.RequiresApproval(adjustment) {
+		adjustment.Status = models.AdjustmentPending
+		err := db.Create(&adjustment).Error
+		return AdjustmentResult{Adjustment: adjustment, Pending: true}, err
+	}
+
+	result := AdjustmentResult{Adjustment: adjustment}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&result.Adjustment).Error; err != nil {
+			return err
+		}
+		var err error
+		result.Inventory, err = database.ApplyAdjustment(tx, &result.Adjustment)
+		return err
+	})
+	return result, err
+}
+
+// ListAdjustments retrieves manual adjustments, newest first
+func (s *GormInventoryService) ListAdjustments(ctx context.Context, filter AdjustmentFilter) ([]models.Adjustment, error) {
+	var adjustments []models.Adjustment
+	db := s.DB.WithContext(ctx)
+
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.ProductID != 0 {
+		db = db.Where("product_id = ?", filter.ProductID)
+	}
+
+	err := db.Preload("Product").Order("created_at DESC").Find(&adjustments).Error
+	return adjustments, err
+}
+
+// GetAdjustment retrieves an adjustment with its product
+func (s *GormInventoryService) GetAdjustment(ctx context.Context, id uint) (models.Adjustment, error) {
+	var adjustment models.Adjustment
+	err := s.DB.WithContext(ctx).Preload("Product").First(&adjustment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return adjustment, ErrAdjustmentNotFound
+	}
+	return adjustment, err
+}
+
+// ApproveAdjustment applies a pending adjustment
+func (s *GormInventoryService) ApproveAdjustment(ctx context.Context, id uint, note string) (AdjustmentResult, error) {
+	db := s.DB.WithContext(ctx)
+
+	adjustment, err := findPending(db, id)
+	if err != nil {
+		return AdjustmentResult{Adjustment: adjustment}, err
+	}
+
+	result := AdjustmentResult{Adjustment: adjustment}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Deciding first means only one decision wins and stock is applied once
+		decide(&result.Adjustment, models.AdjustmentApproved, note)
+		if err := saveDecision(tx, result.Adjustment); err != nil {
+			return err
+		}
+
+		var err error
+		result.Inventory, err = database.ApplyAdjustment(tx, &result.Adjustment)
+		return err
+	})
+	switch {
+	case errors.Is(err, ErrAdjustmentNotPending):
+		result.Adjustment, _ = findAdjustment(db, id)
+	case err != nil:
+		result.Adjustment = adjustment
+	}
+	return result, err
+}
+
+// RejectAdjustment discards a pending adjustment without changing stock
+func (s *GormInventoryService) RejectAdjustment(ctx context.Context, id uint, note string) (models.Adjustment, error) {
+	db := s.DB.WithContext(ctx)
+
+	adjustment, err := findPending(db, id)
+	if err != nil {
+		return adjustment, err
+	}
+
+	decide(&adjustment, models.AdjustmentRejected, note)
+	if err := saveDecision(db, adjustment); err != nil {
+		if errors.Is(err, ErrAdjustmentNotPending) {
+			adjustment, _ = findAdjustment(db, id)
+		}
+		return adjustment, err
+	}
+	return adjustment, nil
+}
+
+// RecomputeStock compares the location-level stock with the bins it rolls up
+// and, when apply is set, corrects the rows that drifted
+func (s *GormInventoryService) RecomputeStock(ctx context.Context, apply bool) ([]StockDrift, error) {
+	return database.RecomputeInventory(s.DB.WithContext(ctx), apply)
+}
+
+// findPending loads an adjustment that is still waiting for a decision
+func findPending(db *gorm.DB, id uint) (models.Adjustment, error) {
+	adjustment, err := findAdjustment(db, id)
+	if err != nil {
+		return adjustment, err
+	}
+
+	if adjustment.Status != models.AdjustmentPending {
+		return adjustment, ErrAdjustmentNotPending
+	}
+	return adjustment, nil
+}
+
+// findAdjustment loads an adjustment, mapping a missing one to ErrAdjustmentNotFound
+func findAdjustment(db *gorm.DB, id uint) (models.Adjustment, error) {
+	var adjustment models.Adjustment
+	err := db.First(&adjustment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return adjustment, ErrAdjustmentNotFound
+	}
+	return adjustment, err
+}
+
+// saveDecision records the decision on an adjustment only while it is still
+// pending, returning ErrAdjustmentNotPending when another decision came first
+func saveDecision(db *gorm.DB, adjustment models.Adjustment) error {
+	result := db.Model(&models.Adjustment{}).
+		Where("id = ? AND status = ?", adjustment.ID, models.AdjustmentPending).
+		Updates(map[string]interface{}{
+			"status":        adjustment.Status,
+			"decision_note": adjustment.DecisionNote,
+			"decided_at":    adjustment.DecidedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAdjustmentNotPending
+	}
+	return nil
+}
@@ -0,0 +1,1028 @@
+// services/memory.go
+package services
+
+import (
+	"context"
+	"inventory_system/database"
+	"inventory_system/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore holds products, stock, bins, count sessions, orders, adjustments,
+// users and API keys in memory for the in-memory services, so business rules can be exercised without
+// a database. Stock is kept per location only; bins are checked to exist but hold
+// nothing. Writes are not audited, and there is no tenancy (see the package doc).
+type MemoryStore struct {
+	mu          sync.Mutex
+	lastIDs     map[string]uint
+	products    map[uint]models.Product
+	stock       map[stockKey]int
+	movements   []memoryMovement
+	bins        map[uint]models.Bin
+	sessions    map[uint]models.CountSession
+	lastCounted map[stockKey]time.Time
+	orders      map[uint]models.Order
+	adjustments map[uint]models.Adjustment
+	users       map[uint]models.User
+	apiKeys     map[uint]models.APIKey
+}
+
+type stockKey struct {
+	productID uint
+	location  string
+}
+
+// memoryMovement is an entry of the in-memory stock ledger used for as-of balances
+type memoryMovement struct {
+	key      stockKey
+	quantity int
+	at       time.Time
+}
+
+// NewMemoryStore returns an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		lastIDs:     make(map[string]uint),
+		products:    make(map[uint]models.Product),
+		stock:       make(map[stockKey]int),
+		bins:        make(map[uint]models.Bin),
+		sessions:    make(map[uint]models.CountSession),
+		lastCounted: make(map[stockKey]time.Time),
+		orders:      make(map[uint]models.Order),
+		adjustments: make(map[uint]models.Adjustment),
+		users:       make(map[uint]models.User),
+		apiKeys:     make(map[uint]models.APIKey),
+	}
+}
+
+// PutProduct stores a product as is, assigning an ID when it has none
+func (m *MemoryStore) PutProduct(product models.Product) models.Product {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if product.ID == 0 {
+		product.ID = m.newID("product")
+	}
+	if product.ID > m.lastIDs["product"] {
+		m.lastIDs["product"] = product.ID
+	}
+	m.products[product.ID] = product
+	return product
+}
+
+// PutBin stores a bin, assigning an ID when it has none
+func (m *MemoryStore) PutBin(bin models.Bin) models.Bin {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if bin.ID == 0 {
+		bin.ID = m.newID("bin")
+	}
+	if bin.ID > m.lastIDs["bin"] {
+		m.lastIDs["bin"] = bin.ID
+	}
+	m.bins[bin.ID] = bin
+	return bin
+}
+
+// SetStock sets the quantity of a product held at a location
+func (m *MemoryStore) SetStock(productID uint, location string, quantity int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := stockKey{productID, location}
+	m.move(key, quantity-m.stock[key])
+}
+
+// Stock returns the quantity of a product held at a location
+func (m *MemoryStore) Stock(productID uint, location string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stock[stockKey{productID, location}]
+}
+
+// newID hands out IDs counting up from 1 for each kind of record, as the
+// database does for each table; the caller holds the lock
+func (m *MemoryStore) newID(kind string) uint {
+	m.lastIDs[kind]++
+	return m.lastIDs[kind]
+}
+
+// move changes the stock at key and records it in the ledger; the caller holds the lock
+func (m *MemoryStore) move(key stockKey, quantity int) {
+	m.stock[key] += quantity
+	m.movements = append(m.movements, memoryMovement{key: key, quantity: quantity, at: time.Now()})
+}
+
+// inventory returns the stock at key as an inventory row; the caller holds the lock
+func (m *MemoryStore) inventory(key stockKey, quantity int) models.Inventory {
+	return models.Inventory{
+		ProductID: key.productID,
+		Product:   m.products[key.productID],
+		Quantity:  quantity,
+		Location:  key.location,
+	}
+}
+
+// applyAdjustment changes the stock described by an adjustment; the caller holds the lock
+func (m *MemoryStore) applyAdjustment(adjustment models.Adjustment) (models.Inventory, error) {
+	key := stockKey{adjustment.ProductID, adjustment.Location}
+	quantity := adjustment.Quantity
+	if adjustment.Action == "remove" {
+		if m.stock[key] < quantity {
+			return models.Inventory{}, ErrInsufficientStock
+		}
+		quantity = -quantity
+	}
+	m.move(key, quantity)
+	return m.inventory(key, m.stock[key]), nil
+}
+
+// MemoryProductService is a ProductService over a MemoryStore
+type MemoryProductService struct {
+	Store *MemoryStore
+}
+
+// List retrieves the products matching filter in ID order
+func (s *MemoryProductService) List(ctx context.Context, filter ProductFilter) ([]models.Product, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	products := []models.Product{}
+	for _, product := range s.Store.products {
+		switch {
+		case filter.Category != "" && product.Category != filter.Category,
+			filter.AbcClass != "" && product.AbcClass != filter.AbcClass,
+			filter.XyzClass != "" && product.XyzClass != filter.XyzClass,
+			filter.MinPrice != nil && product.Price < *filter.MinPrice,
+			filter.MaxPrice != nil && product.Price > *filter.MaxPrice:
+			continue
+		}
+		products = append(products, product)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+// Get retrieves a single product
+func (s *MemoryProductService) Get(ctx context.Context, id uint) (models.Product, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	product, ok := s.Store.products[id]
+	if !ok {
+		return product, ErrProductNotFound
+	}
+	return product, nil
+}
+
+// Create adds a product after checking its category
+func (s *MemoryProductService) Create(ctx context.Context, product *models.Product) error {
+	if !contains(DefaultCategories, product.Category) {
+		return ErrInvalidCategory
+	}
+
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	product.ID = s.Store.newID("product")
+	product.CreatedAt = time.Now()
+	product.UpdatedAt = product.CreatedAt
+	s.Store.products[product.ID] = *product
+	return nil
+}
+
+// Update changes the fields set in update and returns the updated product
+func (s *MemoryProductService) Update(ctx context.Context, id uint, update ProductUpdate) (models.Product, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	product, ok := s.Store.products[id]
+	if !ok {
+		return product, ErrProductNotFound
+	}
+	if update.Category != "" && !contains(DefaultCategories, update.Category) {
+		return product, ErrInvalidCategory
+	}
+
+	if update.Name != "" {
+		product.Name = update.Name
+	}
+	if update.Description != "" {
+		product.Description = update.Description
+	}
+	if update.Price != 0 {
+		product.Price = update.Price
+	}
+	if update.StandardCost != 0 {
+		product.StandardCost = update.StandardCost
+	}
+	if update.Category != "" {
+		product.Category = update.Category
+	}
+	product.UpdatedAt = time.Now()
+	s.Store.products[id] = product
+	return product, nil
+}
+
+// SetImage records the path of a product's uploaded image
+func (s *MemoryProductService) SetImage(ctx context.Context, id uint, path string) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	product, ok := s.Store.products[id]
+	if !ok {
+		return ErrProductNotFound
+	}
+	product.ImagePath = path
+	s.Store.products[id] = product
+	return nil
+}
+
+// MemoryInventoryService is an InventoryService over a MemoryStore
+type MemoryInventoryService struct {
+	Store *MemoryStore
+	// Approval holds the thresholds above which adjustments need approval
+	Approval ApprovalPolicy
+}
+
+// List retrieves the stock held, current or as of a past moment, ordered by
+// product and location
+func (s *MemoryInventoryService) List(ctx context.Context, filter InventoryFilter) ([]models.Inventory, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	balances := s.Store.stock
+	if filter.AsOf != nil {
+		balances = make(map[stockKey]int)
+		for _, movement := range s.Store.movements {
+			if !movement.at.After(*filter.AsOf) {
+				balances[movement.key] += movement.quantity
+			}
+		}
+	}
+
+	inventories := []models.Inventory{}
+	for key, quantity := range balances {
+		if (filter.ProductID != 0 && key.productID != filter.ProductID) ||
+			(filter.Location != "" && key.location != filter.Location) {
+			continue
+		}
+		inventories = append(inventories, s.Store.inventory(key, quantity))
+	}
+	sort.Slice(inventories, func(i, j int) bool {
+		if inventories[i].ProductID != inventories[j].ProductID {
+			return inventories[i].ProductID < inventories[j].ProductID
+		}
+		return inventories[i].Location < inventories[j].Location
+	})
+	return inventories, nil
+}
+
+// Adjust applies a manual adjustment, or queues it when the approval policy
+// requires a second pair of eyes
+func (s *MemoryInventoryService) Adjust(ctx context.Context, request AdjustmentRequest) (AdjustmentResult, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	product, ok := s.Store.products[request.ProductID]
+	if !ok {
+		return AdjustmentResult{}, ErrProductNotFound
+	}
+
+	adjustment, err := newAdjustment(request, product)
+	if err != nil {
+		return AdjustmentResult{}, err
+	}
+	if adjustment.BinID != nil {
+		if bin, ok := s.Store.bins[*adjustment.BinID]; !ok || bin.Location != adjustment.Location {
+			return AdjustmentResult{}, ErrBinNotFound
+		}
+	}
+	adjustment.CreatedAt = time.Now()
+
+	if s.Approval.RequiresApproval(adjustment) {
+		adjustment.Status = models.AdjustmentPending
+		adjustment.ID = s.Store.newID("adjustment")
+		s.Store.adjustments[adjustment.ID] = adjustment
+		return AdjustmentResult{Adjustment: adjustment, Pending: true}, nil
+	}
+
+	inventory, err := s.Store.applyAdjustment(adjustment)
+	if err != nil {
+		return AdjustmentResult{}, err
+	}
+	adjustment.ID = s.Store.newID("adjustment")
+	s.Store.adjustments[adjustment.ID] = adjustment
+	return AdjustmentResult{Adjustment: adjustment, Inventory: inventory}, nil
+}
+
+// ListAdjustments retrieves manual adjustments, newest first
+func (s *MemoryInventoryService) ListAdjustments(ctx context.Context, filter AdjustmentFilter) ([]models.Adjustment, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	adjustments := []models.Adjustment{}
+	for _, adjustment := range s.Store.adjustments {
+		if (filter.Status != "" && adjustment.Status != filter.Status) ||
+			(filter.ProductID != 0 && adjustment.ProductID != filter.ProductID) {
+			continue
+		}
+		adjustment.Product = s.Store.products[adjustment.ProductID]
+		adjustments = append(adjustments, adjustment)
+	}
+	sort.Slice(adjustments, func(i, j int) bool { return adjustments[i].ID > adjustments[j].ID })
+	return adjustments, nil
+}
+
+// GetAdjustment retrieves an adjustment with its product
+func (s *MemoryInventoryService) GetAdjustment(ctx context.Context, id uint) (models.Adjustment, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	adjustment, ok := s.Store.adjustments[id]
+	if !ok {
+		return adjustment, ErrAdjustmentNotFound
+	}
+	adjustment.Product = s.Store.products[adjustment.ProductID]
+	return adjustment, nil
+}
+
+// ApproveAdjustment applies a pending adjustment
+func (s *MemoryInventoryService) ApproveAdjustment(ctx context.Context, id uint, note string) (AdjustmentResult, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	adjustment, err := s.findPending(id)
+	if err != nil {
+		return AdjustmentResult{Adjustment: adjustment}, err
+	}
+
+	inventory, err := s.Store.applyAdjustment(adjustment)
+	if err != nil {
+		return AdjustmentResult{Adjustment: adjustment}, err
+	}
+	decide(&adjustment, models.AdjustmentApproved, note)
+	s.Store.adjustments[id] = adjustment
+	return AdjustmentResult{Adjustment: adjustment, Inventory: inventory}, nil
+}
+
+// RejectAdjustment discards a pending adjustment without changing stock
+func (s *MemoryInventoryService) RejectAdjustment(ctx context.Context, id uint, note string) (models.Adjustment, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	adjustment, err := s.findPending(id)
+	if err != nil {
+		return adjustment, err
+	}
+	decide(&adjustment, models.AdjustmentRejected, note)
+	s.Store.adjustments[id] = adjustment
+	return adjustment, nil
+}
+
+// RecomputeStock compares the stock at each location with the sum of its
+// ledger and, when apply is set, corrects the figures that drifted
+func (s *MemoryInventoryService) RecomputeStock(ctx context.Context, apply bool) ([]StockDrift, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	actual := make(map[stockKey]int)
+	for _, movement := range s.Store.movements {
+		actual[movement.key] += movement.quantity
+	}
+	for key := range s.Store.stock {
+		if _, ok := actual[key]; !ok {
+			actual[key] = 0
+		}
+	}
+
+	drifts := []StockDrift{}
+	for key, quantity := range actual {
+		if recorded := s.Store.stock[key]; recorded != quantity {
+			drifts = append(drifts, StockDrift{ProductID: key.productID, Location: key.location, Recorded: recorded, Actual: quantity})
+			if apply {
+				s.Store.stock[key] = quantity
+			}
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].ProductID != drifts[j].ProductID {
+			return drifts[i].ProductID < drifts[j].ProductID
+		}
+		return drifts[i].Location < drifts[j].Location
+	})
+	return drifts, nil
+}
+
+// findPending loads an adjustment still waiting for a decision; the caller holds the lock
+func (s *MemoryInventoryService) findPending(id uint) (models.Adjustment, error) {
+	adjustment, ok := s.Store.adjustments[id]
+	if !ok {
+		return adjustment, ErrAdjustmentNotFound
+	}
+	if adjustment.Status != models.AdjustmentPending {
+		return adjustment, ErrAdjustmentNotPending
+	}
+	return adjustment, nil
+}
+
+// MemoryBinService is a BinService over a MemoryStore. Bins hold no stock, so
+// putaway only checks its input and moves between bins shift stock only when
+// the bins are at different locations.
+type MemoryBinService struct {
+	Store *MemoryStore
+}
+
+// List retrieves bins, optionally at one location, in location and code order
+func (s *MemoryBinService) List(ctx context.Context, location string) ([]models.Bin, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	bins := []models.Bin{}
+	for _, bin := range s.Store.bins {
+		if location == "" || bin.Location == location {
+			bins = append(bins, bin)
+		}
+	}
+	sort.Slice(bins, func(i, j int) bool {
+		if bins[i].Location != bins[j].Location {
+			return bins[i].Location < bins[j].Location
+		}
+		return bins[i].Code < bins[j].Code
+	})
+	return bins, nil
+}
+
+// Get retrieves a single bin
+func (s *MemoryBinService) Get(ctx context.Context, id uint) (models.Bin, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	bin, ok := s.Store.bins[id]
+	if !ok {
+		return bin, ErrBinNotFound
+	}
+	return bin, nil
+}
+
+// Stock retrieves the products a bin holds, which is always nothing
+func (s *MemoryBinService) Stock(ctx context.Context, binID uint) ([]models.BinStock, error) {
+	return []models.BinStock{}, nil
+}
+
+// Create adds a bin to a location
+func (s *MemoryBinService) Create(ctx context.Context, bin *models.Bin) error {
+	if bin.Code == models.ReceivingBin {
+		return ErrBinCodeReserved
+	}
+
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	for _, existing := range s.Store.bins {
+		if existing.Location == bin.Location && existing.Code == bin.Code {
+			return ErrBinExists
+		}
+	}
+	bin.ID = s.Store.newID("bin")
+	s.Store.bins[bin.ID] = *bin
+	return nil
+}
+
+// Putaway checks that the bin and product exist and that the bin's location
+// holds the stock
+func (s *MemoryBinService) Putaway(ctx context.Context, request PutawayRequest) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	bin, ok := s.Store.bins[request.BinID]
+	if !ok {
+		return ErrBinNotFound
+	}
+	if _, ok := s.Store.products[request.ProductID]; !ok {
+		return ErrProductNotFound
+	}
+	if s.Store.stock[stockKey{request.ProductID, bin.Location}] < request.Quantity {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+// Move transfers stock between the locations of two bins
+func (s *MemoryBinService) Move(ctx context.Context, request BinMoveRequest) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	from, ok := s.Store.bins[request.FromBinID]
+	if !ok {
+		return ErrBinNotFound
+	}
+	to, ok := s.Store.bins[request.ToBinID]
+	if !ok {
+		return ErrBinNotFound
+	}
+	if _, ok := s.Store.products[request.ProductID]; !ok {
+		return ErrProductNotFound
+	}
+
+	fromKey := stockKey{request.ProductID, from.Location}
+	if s.Store.stock[fromKey] < request.Quantity {
+		return ErrInsufficientStock
+	}
+	if from.Location != to.Location {
+		s.Store.move(fromKey, -request.Quantity)
+		s.Store.move(stockKey{request.ProductID, to.Location}, request.Quantity)
+	}
+	return nil
+}
+
+// MemoryCountService is a CountService over a MemoryStore. Variances are
+// valued at the product's current unit cost.
+type MemoryCountService struct {
+	Store *MemoryStore
+}
+
+// List retrieves count sessions, optionally with one status, newest first
+func (s *MemoryCountService) List(ctx context.Context, status string) ([]models.CountSession, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	sessions := []models.CountSession{}
+	for _, session := range s.Store.sessions {
+		if status == "" || session.Status == status {
+			session.Lines = nil
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID > sessions[j].ID })
+	return sessions, nil
+}
+
+// Get retrieves a count session with its lines and their products
+func (s *MemoryCountService) Get(ctx context.Context, id uint) (models.CountSession, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	session, ok := s.Store.sessions[id]
+	if !ok {
+		return session, ErrCountNotFound
+	}
+	session.Lines = append([]models.CountLine(nil), session.Lines...)
+	for i := range session.Lines {
+		session.Lines[i].Product = s.Store.products[session.Lines[i].ProductID]
+	}
+	return session, nil
+}
+
+// Open starts a count session over the stock in scope and snapshots the
+// expected quantities
+func (s *MemoryCountService) Open(ctx context.Context, request CountRequest) (models.CountSession, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	products := make(map[uint]bool, len(request.ProductIDs))
+	for _, id := range request.ProductIDs {
+		products[id] = true
+	}
+
+	var keys []stockKey
+	for key := range s.Store.stock {
+		if (request.Location == "" || key.location == request.Location) &&
+			(len(products) == 0 || products[key.productID]) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return models.CountSession{}, ErrNothingToCount
+	}
+	return s.open(request.Location, request.Note, keys)
+}
+
+// Schedule opens a count session for the items at a location that are due
+// for counting, at most limit of them when limit is positive
+func (s *MemoryCountService) Schedule(ctx context.Context, location string, limit int) (models.CountSession, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	now := time.Now()
+	var keys []stockKey
+	for key := range s.Store.stock {
+		if key.location != location {
+			continue
+		}
+		frequency, ok := database.CountFrequency[s.Store.products[key.productID].AbcClass]
+		if !ok {
+			frequency = database.CountFrequency["C"]
+		}
+		last, ok := s.Store.lastCounted[key]
+		if !ok || !last.AddDate(0, 0, frequency).After(now) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return models.CountSession{}, ErrNothingToCount
+	}
+
+	sortStockKeys(keys)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return s.open(location, "Scheduled cycle count", keys)
+}
+
+// Submit records counted quantities on the lines of an open session
+func (s *MemoryCountService) Submit(ctx context.Context, id uint, entries []CountEntry) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	session, err := s.findOpen(id)
+	if err != nil {
+		return err
+	}
+
+	lines := append([]models.CountLine(nil), session.Lines...)
+	now := time.Now()
+	for _, entry := range entries {
+		found := false
+		for i := range lines {
+			if lines[i].ID == entry.LineID {
+				quantity := entry.Quantity
+				lines[i].CountedQuantity = &quantity
+				lines[i].CountedAt = &now
+				found = true
+			}
+		}
+		if !found {
+			return ErrCountLineNotFound
+		}
+	}
+	session.Lines = lines
+	s.Store.sessions[id] = session
+	return nil
+}
+
+// Variance reports expected against counted quantities for a session
+func (s *MemoryCountService) Variance(ctx context.Context, id uint) (CountVarianceReport, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	session, ok := s.Store.sessions[id]
+	if !ok {
+		return CountVarianceReport{}, ErrCountNotFound
+	}
+	return s.report(session), nil
+}
+
+// Approve posts the variances of a fully counted session and closes it
+func (s *MemoryCountService) Approve(ctx context.Context, id uint) (CountVarianceReport, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	session, err := s.findOpen(id)
+	if err != nil {
+		return CountVarianceReport{Session: session}, err
+	}
+	if report := s.report(session); report.UncountedLines > 0 {
+		return CountVarianceReport{Session: session, UncountedLines: report.UncountedLines}, ErrCountIncomplete
+	}
+
+	// Check every loss before posting any, so a failed approval changes nothing
+	for _, line := range session.Lines {
+		variance := *line.CountedQuantity - line.ExpectedQuantity
+		if s.Store.stock[stockKey{line.ProductID, line.Location}]+variance < 0 {
+			return CountVarianceReport{Session: session}, ErrInsufficientStock
+		}
+	}
+
+	// Closing before posting keeps the count's own movements out of the
+	// movements flagged as made while the count was open
+	now := time.Now()
+	session.Status = models.CountApproved
+	session.ClosedAt = &now
+	for _, line := range session.Lines {
+		key := stockKey{line.ProductID, line.Location}
+		if variance := *line.CountedQuantity - line.ExpectedQuantity; variance != 0 {
+			s.Store.move(key, variance)
+		}
+		s.Store.lastCounted[key] = now
+	}
+	s.Store.sessions[id] = session
+	return s.report(session), nil
+}
+
+// Cancel closes an open count session without posting any adjustments
+func (s *MemoryCountService) Cancel(ctx context.Context, id uint) (models.CountSession, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	session, err := s.findOpen(id)
+	if err != nil {
+		return session, err
+	}
+
+	now := time.Now()
+	session.Status = models.CountCancelled
+	session.ClosedAt = &now
+	s.Store.sessions[id] = session
+	return session, nil
+}
+
+// open creates a session with one line per stock key, refusing keys already
+// part of an open count; the caller holds the lock
+func (s *MemoryCountService) open(location, note string, keys []stockKey) (models.CountSession, error) {
+	for _, session := range s.Store.sessions {
+		if session.Status != models.CountOpen {
+			continue
+		}
+		for _, line := range session.Lines {
+			for _, key := range keys {
+				if line.ProductID == key.productID && line.Location == key.location {
+					return models.CountSession{}, ErrCountOverlap
+				}
+			}
+		}
+	}
+
+	sortStockKeys(keys)
+	session := models.CountSession{
+		ID:        s.Store.newID("count_session"),
+		Location:  location,
+		Status:    models.CountOpen,
+		Note:      note,
+		CreatedAt: time.Now(),
+	}
+	for _, key := range keys {
+		session.Lines = append(session.Lines, models.CountLine{
+			ID:               s.Store.newID("count_line"),
+			SessionID:        session.ID,
+			ProductID:        key.productID,
+			Location:         key.location,
+			ExpectedQuantity: s.Store.stock[key],
+		})
+	}
+	s.Store.sessions[session.ID] = session
+	return session, nil
+}
+
+// findOpen loads a count session that is still open; the caller holds the lock
+func (s *MemoryCountService) findOpen(id uint) (models.CountSession, error) {
+	session, ok := s.Store.sessions[id]
+	if !ok {
+		return session, ErrCountNotFound
+	}
+	if session.Status != models.CountOpen {
+		return session, ErrCountNotOpen
+	}
+	return session, nil
+}
+
+// report computes per-line and total variances for a session; the caller holds the lock
+func (s *MemoryCountService) report(session models.CountSession) CountVarianceReport {
+	report := CountVarianceReport{Session: session, Lines: []CountVariance{}}
+	report.Session.Lines = nil
+
+	for _, line := range session.Lines {
+		product := s.Store.products[line.ProductID]
+		variance := CountVariance{
+			LineID:           line.ID,
+			ProductID:        line.ProductID,
+			ProductName:      product.Name,
+			Location:         line.Location,
+			Expected:         line.ExpectedQuantity,
+			Counted:          line.CountedQuantity,
+			FlaggedMovements: s.flaggedMovements(session, stockKey{line.ProductID, line.Location}),
+		}
+
+		if line.CountedQuantity == nil {
+			report.UncountedLines++
+		} else {
+			diff := *line.CountedQuantity - line.ExpectedQuantity
+			value := float64(diff) * database.CurrentUnitCost(product)
+			variance.Variance = &diff
+			variance.VarianceValue = &value
+			report.TotalVariance += diff
+			report.TotalVarianceValue += value
+		}
+
+		report.Lines = append(report.Lines, variance)
+	}
+	return report
+}
+
+// flaggedMovements counts the movements at key made while a session was open;
+// the caller holds the lock
+func (s *MemoryCountService) flaggedMovements(session models.CountSession, key stockKey) int {
+	flagged := 0
+	for _, movement := range s.Store.movements {
+		if movement.key == key && !movement.at.Before(session.CreatedAt) &&
+			(session.ClosedAt == nil || movement.at.Before(*session.ClosedAt)) {
+			flagged++
+		}
+	}
+	return flagged
+}
+
+// sortStockKeys orders stock keys by location and product, as count lines are
+func sortStockKeys(keys []stockKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].location != keys[j].location {
+			return keys[i].location < keys[j].location
+		}
+		return keys[i].productID < keys[j].productID
+	})
+}
+
+// MemoryOrderService is an OrderService over a MemoryStore
+type MemoryOrderService struct {
+	Store *MemoryStore
+}
+
+// List retrieves all orders in ID order
+func (s *MemoryOrderService) List(ctx context.Context) ([]models.Order, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	orders := []models.Order{}
+	for _, order := range s.Store.orders {
+		order.Product = s.Store.products[order.ProductID]
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].OrderID < orders[j].OrderID })
+	return orders, nil
+}
+
+// Get retrieves a single order with its product
+func (s *MemoryOrderService) Get(ctx context.Context, id uint) (models.Order, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	order, ok := s.Store.orders[id]
+	if !ok {
+		return order, ErrOrderNotFound
+	}
+	order.Product = s.Store.products[order.ProductID]
+	return order, nil
+}
+
+// Create places an order against the stock at a location. Cost of goods is
+// taken at the product's current unit cost.
+func (s *MemoryOrderService) Create(ctx context.Context, request OrderRequest) (models.Order, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	product, ok := s.Store.products[request.ProductID]
+	if !ok {
+		return models.Order{}, ErrProductNotFound
+	}
+
+	key := stockKey{request.ProductID, orDefaultLocation(request.Location)}
+	if s.Store.stock[key] < request.Quantity {
+		return models.Order{}, ErrInsufficientStock
+	}
+	s.Store.move(key, -request.Quantity)
+
+	order := models.Order{
+		OrderID:     s.Store.newID("order"),
+		ProductID:   product.ID,
+		Product:     product,
+		Quantity:    request.Quantity,
+		Location:    key.location,
+		OrderDate:   time.Now(),
+		TotalPrice:  product.Price * float64(request.Quantity),
+		CostOfGoods: database.CurrentUnitCost(product) * float64(request.Quantity),
+	}
+	s.Store.orders[order.OrderID] = order
+	return order, nil
+}
+
+// MemoryUserService is a UserService over a MemoryStore
+type MemoryUserService struct {
+	Store *MemoryStore
+}
+
+// Get retrieves an active user
+func (s *MemoryUserService) Get(id uint) (models.User, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	user, ok := s.Store.users[id]
+	if !ok || !user.Active {
+		return models.User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Authenticate returns the active user with the username and password
+func (s *MemoryUserService) Authenticate(username, password string) (models.User, error) {
+	s.Store.mu.Lock()
+	var found models.User
+	for _, user := range s.Store.users {
+		if user.Username == username && user.Active {
+			found = user
+		}
+	}
+	s.Store.mu.Unlock()
+
+	return found, checkPassword(found, password)
+}
+
+// Create adds an active user with a hashed password
+func (s *MemoryUserService) Create(request UserRequest) (models.User, error) {
+	user, err := newUser(request)
+	if err != nil {
+		return user, err
+	}
+
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	for _, existing := range s.Store.users {
+		if existing.Username == user.Username {
+			return user, ErrUsernameTaken
+		}
+	}
+	user.ID = s.Store.newID("user")
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	s.Store.users[user.ID] = user
+	return user, nil
+}
+
+// MemoryAPIKeyService is an APIKeyService over a MemoryStore
+type MemoryAPIKeyService struct {
+	Store *MemoryStore
+}
+
+// Create issues a new key and returns its record and the key itself
+func (s *MemoryAPIKeyService) Create(ctx context.Context, request APIKeyRequest) (models.APIKey, string, error) {
+	key, record, err := newAPIKey(request)
+	if err != nil {
+		return record, "", err
+	}
+
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	record.ID = s.Store.newID("api_key")
+	record.CreatedAt = time.Now()
+	s.Store.apiKeys[record.ID] = record
+	return record, key, nil
+}
+
+// List retrieves all keys in ID order
+func (s *MemoryAPIKeyService) List(ctx context.Context) ([]models.APIKey, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	keys := []models.APIKey{}
+	for _, key := range s.Store.apiKeys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+// Revoke stops a key from authenticating
+func (s *MemoryAPIKeyService) Revoke(ctx context.Context, id uint) (models.APIKey, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	record, ok := s.Store.apiKeys[id]
+	if !ok {
+		return record, ErrAPIKeyNotFound
+	}
+	if record.RevokedAt != nil {
+		return record, ErrAPIKeyRevoked
+	}
+	now := time.Now()
+	record.RevokedAt = &now
+	s.Store.apiKeys[id] = record
+	return record, nil
+}
+
+// Authenticate returns the usable key with the given value and records its use
+func (s *MemoryAPIKeyService) Authenticate(key string) (models.APIKey, error) {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	hash := HashAPIKey(key)
+	now := time.Now()
+	for id, record := range s.Store.apiKeys {
+		if record.KeyHash != hash {
+			continue
+		}
+		if !usableAPIKey(record, now) {
+			return record, ErrInvalidAPIKey
+		}
+		if staleAPIKeyUse(record, now) {
+			record.LastUsedAt = &now
+			s.Store.apiKeys[id] = record
+		}
+		return record, nil
+	}
+	return models.APIKey{}, ErrInvalidAPIKey
+}
@@ -0,0 +1,185 @@
+// services/memory_test.go
+package services
+
+import (
+	"context"
+	"errors"
+	"inventory_system/models"
+	"testing"
+)
+
+func TestMemoryOrderServiceRejectsInsufficientStock(t *testing.T) {
+	store := NewMemoryStore()
+	product := store.PutProduct(models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25})
+	store.SetStock(product.ID, "Store 1", 5)
+	orders := &MemoryOrderService{Store: store}
+	ctx := context.Background()
+
+	_, err := orders.Create(ctx, OrderRequest{ProductID: product.ID, Quantity: 6, Location: "Store 1"})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("ordering more than the stock returned %v, want ErrInsufficientStock", err)
+	}
+	if stock := store.Stock(product.ID, "Store 1"); stock != 5 {
+		t.Errorf("stock is %d after a rejected order, want 5", stock)
+	}
+	if placed, _ := orders.List(ctx); len(placed) != 0 {
+		t.Errorf("%d orders were placed, want none", len(placed))
+	}
+
+	order, err := orders.Create(ctx, OrderRequest{ProductID: product.ID, Quantity: 5, Location: "Store 1"})
+	if err != nil {
+		t.Fatalf("ordering the whole stock: %v", err)
+	}
+	if order.TotalPrice != 125 {
+		t.Errorf("order total is %.2f, want 125", order.TotalPrice)
+	}
+	if stock := store.Stock(product.ID, "Store 1"); stock != 0 {
+		t.Errorf("stock is %d after the order, want 0", stock)
+	}
+}
+
+func TestMemoryInventoryServiceQueuesLargeAdjustments(t *testing.T) {
+	store := NewMemoryStore()
+	product := store.PutProduct(models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25, StandardCost: 10})
+	store.SetStock(product.ID, "Store 1", 30)
+	inventory := &MemoryInventoryService{Store: store, Approval: ApprovalPolicy{MaxQuantity: 10}}
+	ctx := context.Background()
+
+	small, err := inventory.Adjust(ctx, AdjustmentRequest{ProductID: product.ID, Location: "Store 1", Action: "remove", Quantity: 4, Reason: models.ReasonDamage})
+	if err != nil {
+		t.Fatalf("adjusting: %v", err)
+	}
+	if small.Pending || small.Inventory.Quantity != 26 {
+		t.Errorf("small adjustment left pending %v with stock %d, want applied with 26", small.Pending, small.Inventory.Quantity)
+	}
+	if small.Adjustment.Value != 40 {
+		t.Errorf("adjustment is valued at %.2f, want 40 at cost", small.Adjustment.Value)
+	}
+
+	large, err := inventory.Adjust(ctx, AdjustmentRequest{ProductID: product.ID, Location: "Store 1", Action: "remove", Quantity: 20, Reason: models.ReasonTheft})
+	if err != nil {
+		t.Fatalf("adjusting: %v", err)
+	}
+	if !large.Pending || store.Stock(product.ID, "Store 1") != 26 {
+		t.Fatalf("large adjustment was applied, want it queued for approval")
+	}
+
+	approved, err := inventory.ApproveAdjustment(ctx, large.Adjustment.ID, "confirmed")
+	if err != nil {
+		t.Fatalf("approving: %v", err)
+	}
+	if approved.Adjustment.Status != models.AdjustmentApproved || approved.Inventory.Quantity != 6 {
+		t.Errorf("approval left status %s with stock %d, want approved with 6", approved.Adjustment.Status, approved.Inventory.Quantity)
+	}
+	if _, err := inventory.ApproveAdjustment(ctx, large.Adjustment.ID, ""); !errors.Is(err, ErrAdjustmentNotPending) {
+		t.Errorf("approving twice returned %v, want ErrAdjustmentNotPending", err)
+	}
+
+	if _, err := inventory.Adjust(ctx, AdjustmentRequest{ProductID: product.ID, Location: "Store 1", Action: "remove", Quantity: 7, Reason: models.ReasonDamage}); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("removing more than the stock returned %v, want ErrInsufficientStock", err)
+	}
+}
+
+func TestMemoryProductServiceFiltersByAbcClass(t *testing.T) {
+	store := NewMemoryStore()
+	lamp := store.PutProduct(models.Product{Name: "Desk Lamp", Category: "Furniture", AbcClass: "A", XyzClass: "X"})
+	store.PutProduct(models.Product{Name: "Sofa", Category: "Furniture", AbcClass: "C", XyzClass: "Z"})
+	phone := store.PutProduct(models.Product{Name: "Phone", Category: "Electronics", AbcClass: "A", XyzClass: "Y"})
+	store.PutProduct(models.Product{Name: "Unclassified", Category: "Electronics"})
+	products := &MemoryProductService{Store: store}
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		filter ProductFilter
+		want   []uint
+	}{
+		{"class A", ProductFilter{AbcClass: "A"}, []uint{lamp.ID, phone.ID}},
+		{"class A and X", ProductFilter{AbcClass: "A", XyzClass: "X"}, []uint{lamp.ID}},
+		{"class A electronics", ProductFilter{AbcClass: "A", Category: "Electronics"}, []uint{phone.ID}},
+		{"class B", ProductFilter{AbcClass: "B"}, []uint{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			found, err := products.List(ctx, test.filter)
+			if err != nil {
+				t.Fatalf("listing: %v", err)
+			}
+			got := []uint{}
+			for _, product := range found {
+				got = append(got, product.ID)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got products %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("got products %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCountServicePostsVarianceOnApproval(t *testing.T) {
+	store := NewMemoryStore()
+	product := store.PutProduct(models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25, StandardCost: 10})
+	store.SetStock(product.ID, "Store 1", 20)
+	counts := &MemoryCountService{Store: store}
+	ctx := context.Background()
+
+	session, err := counts.Open(ctx, CountRequest{Location: "Store 1"})
+	if err != nil {
+		t.Fatalf("opening a count: %v", err)
+	}
+	if _, err := counts.Open(ctx, CountRequest{ProductIDs: []uint{product.ID}}); !errors.Is(err, ErrCountOverlap) {
+		t.Errorf("opening an overlapping count returned %v, want ErrCountOverlap", err)
+	}
+	if _, err := counts.Approve(ctx, session.ID); !errors.Is(err, ErrCountIncomplete) {
+		t.Errorf("approving before counting returned %v, want ErrCountIncomplete", err)
+	}
+
+	if err := counts.Submit(ctx, session.ID, []CountEntry{{LineID: session.Lines[0].ID, Quantity: 17}}); err != nil {
+		t.Fatalf("submitting counts: %v", err)
+	}
+	report, err := counts.Approve(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("approving: %v", err)
+	}
+	if report.TotalVariance != -3 || report.TotalVarianceValue != -30 {
+		t.Errorf("variance is %d valued at %.2f, want -3 valued at -30", report.TotalVariance, report.TotalVarianceValue)
+	}
+	if stock := store.Stock(product.ID, "Store 1"); stock != 17 {
+		t.Errorf("stock is %d after approval, want 17", stock)
+	}
+
+	if _, err := counts.Cancel(ctx, session.ID); !errors.Is(err, ErrCountNotOpen) {
+		t.Errorf("cancelling an approved count returned %v, want ErrCountNotOpen", err)
+	}
+	if err := counts.Submit(ctx, session.ID, []CountEntry{{LineID: session.Lines[0].ID, Quantity: 20}}); !errors.Is(err, ErrCountNotOpen) {
+		t.Errorf("submitting to an approved count returned %v, want ErrCountNotOpen", err)
+	}
+}
+
+func TestMemoryBinServiceMovesStockBetweenLocations(t *testing.T) {
+	store := NewMemoryStore()
+	product := store.PutProduct(models.Product{Name: "Desk Lamp", Category: "Furniture", Price: 25})
+	store.SetStock(product.ID, "Store 1", 5)
+	from := store.PutBin(models.Bin{Location: "Store 1", Code: "A-1"})
+	to := store.PutBin(models.Bin{Location: "Store 2", Code: "A-1"})
+	bins := &MemoryBinService{Store: store}
+	ctx := context.Background()
+
+	if err := bins.Move(ctx, BinMoveRequest{ProductID: product.ID, FromBinID: from.ID, ToBinID: to.ID, Quantity: 6}); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("moving more than the stock returned %v, want ErrInsufficientStock", err)
+	}
+	if err := bins.Move(ctx, BinMoveRequest{ProductID: product.ID + 1, FromBinID: from.ID, ToBinID: to.ID, Quantity: 1}); !errors.Is(err, ErrProductNotFound) {
+		t.Errorf("moving an unknown product returned %v, want ErrProductNotFound", err)
+	}
+	if err := bins.Move(ctx, BinMoveRequest{ProductID: product.ID, FromBinID: from.ID, ToBinID: to.ID, Quantity: 2}); err != nil {
+		t.Fatalf("moving: %v", err)
+	}
+	if store.Stock(product.ID, "Store 1") != 3 || store.Stock(product.ID, "Store 2") != 2 {
+		t.Errorf("stock is %d and %d after the move, want 3 and 2", store.Stock(product.ID, "Store 1"), store.Stock(product.ID, "Store 2"))
+	}
+}
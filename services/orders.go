@@ -0,0 +1,111 @@
+// services/orders.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory_system/database"
+	"inventory_system/models"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormOrderService is the OrderService backed by the database
+type GormOrderService struct {
+	DB *gorm.DB
+}
+
+// List retrieves all orders with their products
+func (s *GormOrderService) List(ctx context.Context) ([]models.Order, error) {
+	var orders []models.Order
+	err := s.DB.WithContext(ctx).Preload("Product").Find(&orders).Error
+	return orders, err
+}
+
+// Get retrieves a single order with its product
+func (s *GormOrderService) Get(ctx context.Context, id uint) (models.Order, error) {
+	var order models.Order
+	err := s.DB.WithContext(ctx).Preload("Product").First(&order, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return order, ErrOrderNotFound
+	}
+	return order, err
+}
+
+// Create places an order, picking the stock from the location's bins and
+// costing it under the costing method, all in one transaction. It fails with
+// ErrInsufficientStock when the location holds too little stock.
+func (s *GormOrderService) Create(ctx context.Context, request OrderRequest) (models.Order, error) {
+	db := s.DB.WithContext(ctx)
+
+	// Check if product exists
+	var product models.Product
+	if err := db.First(&product, request.ProductID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Order{}, ErrProductNotFound
+		}
+		return models.Order{}, err
+	}
+
+	location := orDefaultLocation(request.Location)
+	if err := checkLocation(db, location); err != nil {
+		return models.Order{}, err
+	}
+
+	order := models.Order{
+		ProductID:  request.ProductID,
+		Quantity:   request.Quantity,
+		Location:   location,
+		OrderDate:  time.Now(),
+		TotalPrice: product.Price * float64(request.Quantity),
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		// Picking from the location's bins checks the stock under lock, so of
+		// two concurrent orders for the last units one fails with
+		// ErrInsufficientStock
+		if err := database.PickFromLocation(tx, order.ProductID, location, order.Quantity); err != nil {
+			return err
+		}
+		movement := models.StockMovement{
+			ProductID: order.ProductID,
+			Location:  location,
+			Quantity:  -order.Quantity,
+			Type:      models.MovementOrder,
+			Reference: fmt.Sprintf("order:%d", order.OrderID),
+		}
+		if err := database.RecordMovement(tx, &movement); err != nil {
+			return err
+		}
+
+		// Cost of goods sold follows the costing method used to value the movement
+		order.CostOfGoods = -movement.CostValue
+		if err := tx.Model(&order).UpdateColumn("cost_of_goods", order.CostOfGoods).Error; err != nil {
+			return err
+		}
+		orderID := strconv.FormatUint(uint64(order.OrderID), 10)
+		if err := database.RecordAudit(tx, models.EntityOrder, orderID, nil, order); err != nil {
+			return err
+		}
+		created := order
+		created.Product = product
+		if err := database.RecordEvent(tx, models.EntityOrder, orderID, models.EventOrderCreated, created); err != nil {
+			return err
+		}
+		_, err := database.SyncInventory(tx, order.ProductID, location)
+		return err
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	// Fetch the complete order with product details
+	return s.Get(ctx, order.OrderID)
+}
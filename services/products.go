@@ -0,0 +1,144 @@
+// services/products.go
+package services
+
+import (
+	"context"
+	"errors"
+	"inventory_system/database"
+	"inventory_system/models"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// GormProductService is the ProductService backed by the database
+type GormProductService struct {
+	DB *gorm.DB
+}
+
+// List retrieves the products matching filter
+func (s *GormProductService) List(ctx context.Context, filter ProductFilter) ([]models.Product, error) {
+	var products []models.Product
+	db := s.DB.WithContext(ctx)
+
+	if filter.Category != "" {
+		db = db.Where("category = ?", filter.Category)
+	}
+	if filter.AbcClass != "" {
+		db = db.Where("abc_class = ?", filter.AbcClass)
+	}
+	if filter.XyzClass != "" {
+		db = db.Where("xyz_class = ?", filter.XyzClass)
+	}
+	if filter.MinPrice != nil {
+		db = db.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		db = db.Where("price <= ?", *filter.MaxPrice)
+	}
+
+	err := db.Find(&products).Error
+	return products, err
+}
+
+// Get retrieves a single product
+func (s *GormProductService) Get(ctx context.Context, id uint) (models.Product, error) {
+	var product models.Product
+	err := s.DB.WithContext(ctx).First(&product, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return product, ErrProductNotFound
+	}
+	return product, err
+}
+
+// Create adds a product after checking its category against the tenant's
+func (s *GormProductService) Create(ctx context.Context, product *models.Product) error {
+	db := s.DB.WithContext(ctx)
+
+	if err := checkCategory(db, product.Category); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		return database.RecordAudit(tx, models.EntityProduct, productEntityID(product.ID), nil, *product)
+	})
+}
+
+// Update changes the fields set in update and returns the updated product
+func (s *GormProductService) Update(ctx context.Context, id uint, update ProductUpdate) (models.Product, error) {
+	db := s.DB.WithContext(ctx)
+
+	product, err := s.Get(ctx, id)
+	if err != nil {
+		return product, err
+	}
+
+	// Apply updates only for fields that were provided
+	updates := make(map[string]interface{})
+	if update.Name != "" {
+		updates["name"] = update.Name
+	}
+	if update.Description != "" {
+		updates["description"] = update.Description
+	}
+	if update.Price != 0 {
+		updates["price"] = update.Price
+	}
+	if update.StandardCost != 0 {
+		updates["standard_cost"] = update.StandardCost
+	}
+	if update.Category != "" {
+		if err := checkCategory(db, update.Category); err != nil {
+			return product, err
+		}
+		updates["category"] = update.Category
+	}
+
+	if len(updates) == 0 {
+		return product, nil
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return updateAudited(tx, id, updates)
+	})
+	if err != nil {
+		return product, err
+	}
+
+	return s.Get(ctx, id)
+}
+
+// SetImage records the path of a product's uploaded image
+func (s *GormProductService) SetImage(ctx context.Context, id uint, path string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateAudited(tx, id, map[string]interface{}{"image_path": path})
+	})
+}
+
+// updateAudited changes columns of a product, audits the change and records
+// product.updated in the outbox
+func updateAudited(tx *gorm.DB, id uint, updates map[string]interface{}) error {
+	var before, after models.Product
+	if err := tx.First(&before, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		return err
+	}
+	if err := tx.Model(&models.Product{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return err
+	}
+	if err := tx.First(&after, id).Error; err != nil {
+		return err
+	}
+	if err := database.RecordAudit(tx, models.EntityProduct, productEntityID(id), before, after); err != nil {
+		return err
+	}
+	return database.RecordEvent(tx, models.EntityProduct, productEntityID(id), models.EventProductUpdated, after)
+}
+
+// productEntityID is the audit entity ID of a product
+func productEntityID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,368 @@
+// services/services.go
+
+// Package services holds the business rules of the inventory system behind
+// interfaces the handlers and CLI commands call. The Gorm services work on the
+// database and keep each tenant's data apart through the tenant in the
+// context. The Memory services work on a MemoryStore so the rules can be
+// exercised without a database; they model a single tenant that uses the
+// default categories at any location, and ignore the tenant in the context.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"inventory_system/auth"
+	"inventory_system/database"
+	"inventory_system/models"
+	"strings"
+	"time"
+)
+
+// DefaultLocation is used when an order or adjustment does not name a location
+const DefaultLocation = "Warehouse A"
+
+// Errors returned by the services; handlers map them to HTTP statuses
+var (
+	ErrProductNotFound      = errors.New("product not found")
+	ErrOrderNotFound        = errors.New("order not found")
+	ErrAdjustmentNotFound   = errors.New("adjustment not found")
+	ErrBinNotFound          = errors.New("bin not found at this location")
+	ErrInvalidCategory      = errors.New("invalid category")
+	ErrUnknownLocation      = errors.New("location is not one of the tenant's locations")
+	ErrTenantNotFound       = errors.New("tenant not found")
+	ErrTenantNameRequired   = errors.New("tenant name is required")
+	ErrTenantNameTaken      = errors.New("tenant name is already taken")
+	ErrCategoriesRequired   = errors.New("tenant needs at least one category")
+	ErrInvalidAction        = errors.New("action must be add or remove")
+	ErrAdjustmentNotPending = errors.New("adjustment is not pending")
+	ErrAPIKeyNameRequired   = errors.New("API key name is required")
+	ErrAPIKeyScopesRequired = errors.New("API key needs at least one scope")
+	ErrInvalidScope         = errors.New("unknown scope")
+	ErrAPIKeyExpiryPast     = errors.New("API key expiry must be in the future")
+	ErrAPIKeyNotFound       = errors.New("API key not found")
+	ErrAPIKeyRevoked        = errors.New("API key is already revoked")
+	ErrInvalidAPIKey        = auth.ErrInvalidAPIKey
+	ErrUserNotFound         = errors.New("user not found")
+	ErrUsernameRequired     = errors.New("username is required")
+	ErrUsernameTaken        = errors.New("username is already taken")
+	ErrInvalidRole          = fmt.Errorf("role must be one of %s", strings.Join(auth.RoleNames(), ", "))
+	ErrPasswordTooShort     = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	ErrInvalidCredentials   = errors.New("invalid username or password")
+	ErrInsufficientStock    = database.ErrInsufficientStock
+	ErrBinExists            = errors.New("bin already exists at this location")
+	ErrBinCodeReserved      = errors.New("bin code is reserved")
+	ErrCountNotFound        = errors.New("count session not found")
+	ErrCountLineNotFound    = errors.New("count line not found in this session")
+	ErrCountNotOpen         = errors.New("count session is no longer open")
+	ErrCountIncomplete      = errors.New("count session has uncounted lines")
+	ErrNothingToCount       = errors.New("no inventory matches the count scope")
+	ErrCountOverlap         = database.ErrCountOverlap
+)
+
+// DefaultCategories are the categories new tenants file products under until
+// they configure their own
+var DefaultCategories = []string{"Electronics", "Apparel", "Footwear", "Furniture", "Appliances"}
+
+// ProductService manages the product catalogue
+type ProductService interface {
+	List(ctx context.Context, filter ProductFilter) ([]models.Product, error)
+	Get(ctx context.Context, id uint) (models.Product, error)
+	Create(ctx context.Context, product *models.Product) error
+	Update(ctx context.Context, id uint, update ProductUpdate) (models.Product, error)
+	SetImage(ctx context.Context, id uint, path string) error
+}
+
+// InventoryService reads stock levels and applies manual adjustments, queueing
+// the large ones for approval
+type InventoryService interface {
+	List(ctx context.Context, filter InventoryFilter) ([]models.Inventory, error)
+	Adjust(ctx context.Context, request AdjustmentRequest) (AdjustmentResult, error)
+	ListAdjustments(ctx context.Context, filter AdjustmentFilter) ([]models.Adjustment, error)
+	GetAdjustment(ctx context.Context, id uint) (models.Adjustment, error)
+	ApproveAdjustment(ctx context.Context, id uint, note string) (AdjustmentResult, error)
+	RejectAdjustment(ctx context.Context, id uint, note string) (models.Adjustment, error)
+	RecomputeStock(ctx context.Context, apply bool) ([]StockDrift, error)
+}
+
+// OrderService places orders against the stock at a location
+type OrderService interface {
+	List(ctx context.Context) ([]models.Order, error)
+	Get(ctx context.Context, id uint) (models.Order, error)
+	Create(ctx context.Context, request OrderRequest) (models.Order, error)
+}
+
+// BinService manages the bins of each location and moves stock between them
+type BinService interface {
+	List(ctx context.Context, location string) ([]models.Bin, error)
+	Get(ctx context.Context, id uint) (models.Bin, error)
+	Stock(ctx context.Context, binID uint) ([]models.BinStock, error)
+	Create(ctx context.Context, bin *models.Bin) error
+	Putaway(ctx context.Context, request PutawayRequest) error
+	Move(ctx context.Context, request BinMoveRequest) error
+}
+
+// CountService runs blind cycle counts: it opens sessions that snapshot the
+// expected stock, records counts and posts the variances on approval
+type CountService interface {
+	List(ctx context.Context, status string) ([]models.CountSession, error)
+	Get(ctx context.Context, id uint) (models.CountSession, error)
+	Open(ctx context.Context, request CountRequest) (models.CountSession, error)
+	Schedule(ctx context.Context, location string, limit int) (models.CountSession, error)
+	Submit(ctx context.Context, id uint, entries []CountEntry) error
+	Variance(ctx context.Context, id uint) (CountVarianceReport, error)
+	Approve(ctx context.Context, id uint) (CountVarianceReport, error)
+	Cancel(ctx context.Context, id uint) (models.CountSession, error)
+}
+
+// UserService looks up and signs in the users of the API. Usernames are
+// unique across tenants, so users are found before their tenant is known.
+type UserService interface {
+	Get(id uint) (models.User, error)
+	Authenticate(username, password string) (models.User, error)
+	Create(request UserRequest) (models.User, error)
+}
+
+// APIKeyService issues, revokes and checks keys for programmatic clients.
+// Keys are issued to the tenant of the context; Authenticate finds a key of any
+// tenant.
+type APIKeyService interface {
+	Create(ctx context.Context, request APIKeyRequest) (models.APIKey, string, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id uint) (models.APIKey, error)
+	Authenticate(key string) (models.APIKey, error)
+}
+
+// ProductFilter narrows a product listing; empty fields match everything
+type ProductFilter struct {
+	Category string
+	AbcClass string
+	XyzClass string
+	MinPrice *float64
+	MaxPrice *float64
+}
+
+// ProductUpdate holds the product fields to change; zero values are left alone
+type ProductUpdate struct {
+	Name         string
+	Description  string
+	Price        float64
+	Category     string
+	StandardCost float64
+}
+
+// InventoryFilter narrows an inventory listing. With AsOf set the balances are
+// rebuilt from the movement ledger at that moment.
+type InventoryFilter struct {
+	ProductID uint
+	Location  string
+	AsOf      *time.Time
+}
+
+// AdjustmentFilter narrows an adjustment listing
+type AdjustmentFilter struct {
+	Status    string
+	ProductID uint
+}
+
+// AdjustmentRequest describes a manual stock adjustment
+type AdjustmentRequest struct {
+	ProductID uint
+	Location  string
+	// BinID targets a bin; otherwise additions land in receiving and removals
+	// are picked across the location
+	BinID    *uint
+	Action   string
+	Quantity int
+	Reason   string
+	Note     string
+	// UnitCost is the cost of each received unit; it defaults to the current cost
+	UnitCost *float64
+}
+
+// AdjustmentResult is an adjustment and, once applied, the resulting stock
+type AdjustmentResult struct {
+	Adjustment models.Adjustment
+	Inventory  models.Inventory
+	// Pending is set when the adjustment was queued for approval instead
+	Pending bool
+}
+
+// StockDrift is a location-level stock figure that disagrees with the stock it
+// is recomputed from
+type StockDrift = database.StockDrift
+
+// OrderRequest describes an order for a quantity of one product
+type OrderRequest struct {
+	ProductID uint
+	Quantity  int
+	Location  string
+}
+
+// PutawayRequest describes stock moved from a location's receiving bin into a storage bin
+type PutawayRequest struct {
+	ProductID uint
+	BinID     uint
+	Quantity  int
+}
+
+// BinMoveRequest describes stock moved from one bin to another
+type BinMoveRequest struct {
+	ProductID uint
+	FromBinID uint
+	ToBinID   uint
+	Quantity  int
+}
+
+// CountRequest describes the scope of a count: the stock at a location, of a
+// set of products, or of those products at a location
+type CountRequest struct {
+	Location   string
+	ProductIDs []uint
+	Note       string
+}
+
+// CountEntry is the counted quantity of one count line
+type CountEntry struct {
+	LineID   uint
+	Quantity int
+}
+
+// CountVariance compares the expected and counted quantity of one count line
+type CountVariance struct {
+	LineID           uint     `json:"line_id"`
+	ProductID        uint     `json:"product_id"`
+	ProductName      string   `json:"product_name"`
+	Location         string   `json:"location"`
+	Expected         int      `json:"expected_quantity"`
+	Counted          *int     `json:"counted_quantity"`
+	Variance         *int     `json:"variance"`
+	VarianceValue    *float64 `json:"variance_value"`
+	FlaggedMovements int      `json:"flagged_movements"`
+}
+
+// CountVarianceReport summarizes the outcome of a count session
+type CountVarianceReport struct {
+	Session            models.CountSession `json:"session"`
+	Lines              []CountVariance     `json:"lines"`
+	UncountedLines     int                 `json:"uncounted_lines"`
+	TotalVariance      int                 `json:"total_variance"`
+	TotalVarianceValue float64             `json:"total_variance_value"`
+}
+
+// UserRequest describes a new user
+type UserRequest struct {
+	TenantID uint
+	Username string
+	Password string
+	Role     string
+	// Locations restricts the user's stock changes to these locations when not empty
+	Locations []string
+}
+
+// APIKeyRequest describes a new API key
+type APIKeyRequest struct {
+	Name string
+	// Scopes are the permissions the key grants, such as orders:create
+	Scopes []string
+	// Locations restricts the key's stock changes to these locations when not empty
+	Locations []string
+	// ExpiresAt is when the key stops working; nil keys never expire
+	ExpiresAt *time.Time
+}
+
+// ApprovalPolicy decides which manual adjustments go to the approval queue.
+// A zero threshold disables that check.
+type ApprovalPolicy struct {
+	MaxQuantity int
+	MaxValue    float64
+}
+
+// RequiresApproval reports whether an adjustment exceeds the policy thresholds
+func (p ApprovalPolicy) RequiresApproval(adjustment models.Adjustment) bool {
+	if p.MaxQuantity > 0 && adjustment.Quantity > p.MaxQuantity {
+		return true
+	}
+	if p.MaxValue > 0 && adjustment.Value > p.MaxValue {
+		return true
+	}
+	return false
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// newAdjustment builds the adjustment record for a request, valued at cost for
+// the approval thresholds
+func newAdjustment(request AdjustmentRequest, product models.Product) (models.Adjustment, error) {
+	if request.Action != "add" && request.Action != "remove" {
+		return models.Adjustment{}, ErrInvalidAction
+	}
+
+	unitCost := database.CurrentUnitCost(product)
+	if request.UnitCost != nil && request.Action == "add" {
+		unitCost = *request.UnitCost
+	}
+
+	adjustment := models.Adjustment{
+		ProductID: product.ID,
+		Location:  orDefaultLocation(request.Location),
+		BinID:     request.BinID,
+		Action:    request.Action,
+		Quantity:  request.Quantity,
+		Value:     float64(request.Quantity) * unitCost,
+		Reason:    request.Reason,
+		Note:      request.Note,
+		Status:    models.AdjustmentApplied,
+	}
+	if request.Action == "add" {
+		adjustment.UnitCost = request.UnitCost
+	}
+	return adjustment, nil
+}
+
+// decide records an approval decision on a pending adjustment
+func decide(adjustment *models.Adjustment, status, note string) {
+	now := time.Now()
+	adjustment.Status = status
+	adjustment.DecisionNote = note
+	adjustment.DecidedAt = &now
+}
+
+// orDefaultLocation returns location, or the default location when it is empty
+func orDefaultLocation(location string) string {
+	if location == "" {
+		return DefaultLocation
+	}
+	return location
+}
+
+// The database-backed and, except for tenants, the in-memory services satisfy
+// the interfaces
+var (
+	_ ProductService   = (*GormProductService)(nil)
+	_ ProductService   = (*MemoryProductService)(nil)
+	_ InventoryService = (*GormInventoryService)(nil)
+	_ InventoryService = (*MemoryInventoryService)(nil)
+	_ OrderService     = (*GormOrderService)(nil)
+	_ OrderService     = (*MemoryOrderService)(nil)
+	_ BinService       = (*GormBinService)(nil)
+	_ BinService       = (*MemoryBinService)(nil)
+	_ CountService     = (*GormCountService)(nil)
+	_ CountService     = (*MemoryCountService)(nil)
+	_ UserService      = (*GormUserService)(nil)
+	_ UserService      = (*MemoryUserService)(nil)
+	_ APIKeyService    = (*GormAPIKeyService)(nil)
+	_ APIKeyService    = (*MemoryAPIKeyService)(nil)
+	_ TenantService    = (*GormTenantService)(nil)
+
+	_ auth.KeyAuthenticator = (*GormAPIKeyService)(nil)
+	_ auth.KeyAuthenticator = (*MemoryAPIKeyService)(nil)
+)
@@ -0,0 +1,153 @@
+// services/tenants.go
+package services
+
+import (
+	"context"
+	"errors"
+	"inventory_system/database"
+	"inventory_system/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TenantService manages the businesses sharing the deployment and their
+// categories and locations. Tenants only exist in the database.
+type TenantService interface {
+	Current(ctx context.Context) (models.Tenant, error)
+	List() ([]models.Tenant, error)
+	Create(request TenantRequest) (models.Tenant, error)
+	Update(ctx context.Context, request TenantRequest) (models.Tenant, error)
+}
+
+// TenantRequest describes a new tenant or the settings of the current one
+type TenantRequest struct {
+	Name string
+	// Categories are the product categories; DefaultCategories when empty
+	Categories []string
+	// Locations are the stock locations; any location is accepted when empty
+	Locations []string
+}
+
+// GormTenantService is the TenantService backed by the database
+type GormTenantService struct {
+	DB *gorm.DB
+}
+
+// Current retrieves the tenant the context is scoped to
+func (s *GormTenantService) Current(ctx context.Context) (models.Tenant, error) {
+	tenant, err := database.GetTenant(s.DB.WithContext(ctx))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tenant, ErrTenantNotFound
+	}
+	return tenant, err
+}
+
+// List retrieves every tenant, oldest first
+func (s *GormTenantService) List() ([]models.Tenant, error) {
+	return database.GetTenants(s.DB)
+}
+
+// Create adds a tenant
+func (s *GormTenantService) Create(request TenantRequest) (models.Tenant, error) {
+	tenant, err := newTenant(request)
+	if err != nil {
+		return tenant, err
+	}
+	if len(tenant.Categories) == 0 {
+		tenant.Categories = DefaultCategories
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.Tenant{}).Where("name = ?", tenant.Name).Count(&count).Error; err != nil {
+		return tenant, err
+	}
+	if count > 0 {
+		return tenant, ErrTenantNameTaken
+	}
+	return tenant, s.DB.Create(&tenant).Error
+}
+
+// Update replaces the categories and locations of the tenant the context is
+// scoped to, and renames it when a name is given
+func (s *GormTenantService) Update(ctx context.Context, request TenantRequest) (models.Tenant, error) {
+	tenant, err := s.Current(ctx)
+	if err != nil {
+		return tenant, err
+	}
+	if strings.TrimSpace(request.Name) == "" {
+		request.Name = tenant.Name
+	}
+	update, err := newTenant(request)
+	if err != nil {
+		return tenant, err
+	}
+	if len(update.Categories) == 0 {
+		return tenant, ErrCategoriesRequired
+	}
+
+	if update.Name != tenant.Name {
+		var count int64
+		if err := s.DB.Model(&models.Tenant{}).Where("name = ? AND id <> ?", update.Name, tenant.ID).Count(&count).Error; err != nil {
+			return tenant, err
+		}
+		if count > 0 {
+			return tenant, ErrTenantNameTaken
+		}
+	}
+
+	tenant.Name = update.Name
+	tenant.Categories = update.Categories
+	tenant.Locations = update.Locations
+	return tenant, s.DB.Save(&tenant).Error
+}
+
+// newTenant checks a request and builds the tenant it describes
+func newTenant(request TenantRequest) (models.Tenant, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return models.Tenant{}, ErrTenantNameRequired
+	}
+	return models.Tenant{
+		Name:       name,
+		Categories: trimList(request.Categories),
+		Locations:  trimList(request.Locations),
+	}, nil
+}
+
+// trimList trims the values of a list and drops the empty ones
+func trimList(values []string) []string {
+	trimmed := []string{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
+
+// checkCategory returns ErrInvalidCategory unless the tenant of db files
+// products under category
+func checkCategory(db *gorm.DB, category string) error {
+	tenant, err := database.GetTenant(db)
+	if err != nil {
+		return err
+	}
+	if !contains(tenant.Categories, category) {
+		return ErrInvalidCategory
+	}
+	return nil
+}
+
+// checkLocation returns ErrUnknownLocation unless the tenant of db has listed
+// location among its locations or has listed none
+func checkLocation(db *gorm.DB, location string) error {
+	tenant, err := database.GetTenant(db)
+	if err != nil {
+		return err
+	}
+	if !database.HasLocation(tenant, location) {
+		return ErrUnknownLocation
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// services/users.go
+package services
+
+import (
+	"context"
+	"errors"
+	"inventory_system/auth"
+	"inventory_system/database"
+	"inventory_system/models"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// MinPasswordLength is the shortest password a user may be given
+const MinPasswordLength = 8
+
+// GormUserService is the UserService backed by the database
+type GormUserService struct {
+	DB *gorm.DB
+}
+
+// allTenants is a handle that finds users of every tenant
+func (s *GormUserService) allTenants() *gorm.DB {
+	return s.DB.WithContext(database.AllTenants(context.Background()))
+}
+
+// Get retrieves an active user
+func (s *GormUserService) Get(id uint) (models.User, error) {
+	var user models.User
+	err := s.allTenants().Where("active = ?", true).First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, ErrUserNotFound
+	}
+	return user, err
+}
+
+// Authenticate returns the active user with the username and password
+func (s *GormUserService) Authenticate(username, password string) (models.User, error) {
+	var user models.User
+	err := s.allTenants().Where("username = ? AND active = ?", username, true).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, err
+	}
+	return user, checkPassword(user, password)
+}
+
+// Create adds an active user of a tenant with a hashed password
+func (s *GormUserService) Create(request UserRequest) (models.User, error) {
+	user, err := newUser(request)
+	if err != nil {
+		return user, err
+	}
+	db := s.allTenants()
+
+	if err := db.First(&models.Tenant{}, user.TenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, ErrTenantNotFound
+		}
+		return user, err
+	}
+
+	var count int64
+	if err := db.Model(&models.User{}).Where("username = ?", user.Username).Count(&count).Error; err != nil {
+		return user, err
+	}
+	if count > 0 {
+		return user, ErrUsernameTaken
+	}
+	return user, db.Create(&user).Error
+}
+
+// newUser builds an active user, hashing the password with bcrypt
+func newUser(request UserRequest) (models.User, error) {
+	username := strings.TrimSpace(request.Username)
+	if username == "" {
+		return models.User{}, ErrUsernameRequired
+	}
+	if len(request.Password) < MinPasswordLength {
+		return models.User{}, ErrPasswordTooShort
+	}
+	if !auth.IsValidRole(request.Role) {
+		return models.User{}, ErrInvalidRole
+	}
+
+	var locations []string
+	for _, location := range request.Locations {
+		if location = strings.TrimSpace(location); location != "" {
+			locations = append(locations, location)
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+	return models.User{
+		TenantID:     request.TenantID,
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         request.Role,
+		Locations:    locations,
+		Active:       true,
+	}, nil
+}
+
+// dummyHash is compared against when the user does not exist, so that unknown
+// usernames take as long to reject as wrong passwords
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// checkPassword returns ErrInvalidCredentials unless password matches the
+// user's hash; a user that was not found never matches
+func checkPassword(user models.User, password string) error {
+	hash := []byte(user.PasswordHash)
+	if user.ID == 0 {
+		hash = dummyHash()
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || user.ID == 0 {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
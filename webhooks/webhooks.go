@@ -0,0 +1,307 @@
+// webhooks/webhooks.go
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"inventory_system/config"
+	"inventory_system/database"
+	"inventory_system/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Events are the events webhooks can subscribe to
+var Events = []string{
+	models.EventOrderCreated,
+	models.EventInventoryChanged,
+	models.EventInventoryLowStock,
+	models.EventProductUpdated,
+}
+
+// IsValidEvent reports whether webhooks can subscribe to an event
+func IsValidEvent(event string) bool {
+	for _, known := range Events {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// secretPrefix starts every signing secret so that leaked secrets are easy to recognise
+const secretPrefix = "whsec_"
+
+// maxRetryDelay caps the wait between two attempts of a delivery
+const maxRetryDelay = 12 * time.Hour
+
+// batchSize is how many due deliveries one run sends at most
+const batchSize = 100
+
+// Payload is the body posted to a webhook
+type Payload struct {
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Enqueue queues an outbox event for delivery to every active webhook of the
+// tenant of db that subscribes to it. An inventory.changed event is also sent
+// as inventory.low_stock to the webhooks whose threshold the stock has just
+// fallen below. Webhooks the event was already queued for are skipped, so an
+// event published again is not delivered twice.
+func Enqueue(db *gorm.DB, event models.OutboxEvent) error {
+	var hooks []models.Webhook
+	if err := db.Where("active = ?", true).Order("id").Find(&hooks).Error; err != nil {
+		return err
+	}
+	var queued []uint
+	if err := db.Model(&models.WebhookDelivery{}).Where("event_id = ?", event.ID).Pluck("webhook_id", &queued).Error; err != nil {
+		return err
+	}
+
+	var change database.InventoryChange
+	if event.Event == models.EventInventoryChanged {
+		if err := json.Unmarshal([]byte(event.Payload), &change); err != nil {
+			return err
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, webhook := range hooks {
+			if containsID(queued, webhook.ID) {
+				continue
+			}
+			if subscribes(webhook, event.Event) {
+				if err := queue(tx, webhook, event, event.Event, json.RawMessage(event.Payload)); err != nil {
+					return err
+				}
+			}
+			if event.Event == models.EventInventoryChanged && subscribes(webhook, models.EventInventoryLowStock) &&
+				change.PreviousQuantity >= webhook.LowStockThreshold && change.Quantity < webhook.LowStockThreshold {
+				low := change
+				low.Threshold = webhook.LowStockThreshold
+				if err := queue(tx, webhook, event, models.EventInventoryLowStock, low); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// queue adds a pending delivery of an event's data to a webhook
+func queue(tx *gorm.DB, webhook models.Webhook, event models.OutboxEvent, name string, data interface{}) error {
+	payload, err := json.Marshal(Payload{Event: name, OccurredAt: event.CreatedAt, Data: data})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return tx.Create(&models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       &event.ID,
+		Event:         name,
+		Payload:       string(payload),
+		Status:        models.DeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}).Error
+}
+
+// subscribes reports whether a webhook receives an event
+func subscribes(webhook models.Webhook, event string) bool {
+	for _, subscribed := range webhook.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// containsID reports whether ids holds id
+func containsID(ids []uint, id uint) bool {
+	for _, known := range ids {
+		if known == id {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSecret generates a signing secret for a webhook
+func NewSecret() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(secret), nil
+}
+
+// Sign returns the signature of a delivery body sent at a Unix timestamp: the
+// hex HMAC-SHA256, keyed with the webhook's secret, of the timestamp, a dot and
+// the body. Receivers recompute it to check that the delivery came from us and
+// reject old timestamps to stop replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher sends queued deliveries and retries failed ones with exponential backoff
+type Dispatcher struct {
+	Client *http.Client
+	// MaxAttempts is how many times a delivery is tried before it is marked failed
+	MaxAttempts int
+	// RetryDelay is the wait after the first failed attempt; it doubles after each
+	RetryDelay time.Duration
+}
+
+// NewDispatcher takes the delivery settings from the service configuration
+func NewDispatcher(cfg *config.Config) Dispatcher {
+	return Dispatcher{
+		Client:      &http.Client{Timeout: time.Duration(cfg.Webhooks.Timeout)},
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		RetryDelay:  time.Duration(cfg.Webhooks.RetryDelay),
+	}
+}
+
+// Backoff returns the wait before retrying a delivery that has failed attempts times
+func (d Dispatcher) Backoff(attempts int) time.Duration {
+	delay := d.RetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// RunDue sends every pending delivery whose next attempt has come, oldest
+// first. Deliveries of every tenant are sent, each through its own tenant's
+// data.
+func (d Dispatcher) RunDue(ctx context.Context, db *gorm.DB, now time.Time) error {
+	var deliveries []models.WebhookDelivery
+	if err := db.WithContext(database.AllTenants(ctx)).
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("id").Limit(batchSize).
+		Find(&deliveries).Error; err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		tenantDB := db.WithContext(database.WithTenant(ctx, delivery.TenantID))
+
+		// Claim the attempt so that a slow webhook is not sent the delivery twice
+		lease := now.Add(2 * d.Client.Timeout)
+		claimed := tenantDB.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.DeliveryPending, delivery.NextAttemptAt).
+			UpdateColumn("next_attempt_at", lease)
+		if claimed.Error != nil {
+			return claimed.Error
+		}
+		if claimed.RowsAffected == 0 {
+			continue
+		}
+
+		if err := d.attempt(ctx, tenantDB, &delivery, now); err != nil {
+			log.Printf("Webhook delivery %d failed: %v", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// attempt sends a delivery to its webhook and records the outcome, scheduling
+// a retry after a failure until the delivery runs out of attempts
+func (d Dispatcher) attempt(ctx context.Context, db *gorm.DB, delivery *models.WebhookDelivery, now time.Time) error {
+	var webhook models.Webhook
+	err := db.First(&webhook, delivery.WebhookID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = errors.New("webhook was deleted")
+	case err != nil:
+		return err
+	case !webhook.Active:
+		err = errors.New("webhook is inactive")
+	default:
+		delivery.ResponseStatus, err = d.send(ctx, webhook, *delivery, now)
+		delivery.Attempts++
+	}
+
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nil
+	delivery.Error = ""
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliverySucceeded
+	case webhook.Active && delivery.Attempts < d.MaxAttempts:
+		next := now.Add(d.Backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = err.Error()
+	}
+
+	if saveErr := db.Save(delivery).Error; saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// send posts a delivery's payload, signed with the webhook's secret, and
+// returns the response status
+func (d Dispatcher) send(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Webhook-Event", delivery.Event)
+	request.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", "sha256="+Sign(webhook.Secret, timestamp, body))
+
+	response, err := d.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("webhook responded with %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// Replay queues failed deliveries of a webhook to be sent again with a fresh
+// set of attempts: the ones listed in ids, or all of them when ids is empty
+func Replay(db *gorm.DB, webhookID uint, ids []uint, now time.Time) (int64, error) {
+	db = db.Model(&models.WebhookDelivery{}).Where("webhook_id = ? AND status = ?", webhookID, models.DeliveryFailed)
+	if len(ids) > 0 {
+		db = db.Where("id IN ?", ids)
+	}
+	result := db.UpdateColumns(map[string]interface{}{
+		"status":          models.DeliveryPending,
+		"attempts":        0,
+		"error":           "",
+		"response_status": 0,
+		"next_attempt_at": now,
+	})
+	return result.RowsAffected, result.Error
+}